@@ -1,19 +1,60 @@
 package api
 
-import controllerV1 "healthcheck/api/controller/v1"
+import (
+	controllerV1 "healthcheck/api/controller/v1"
+	"healthcheck/pkg/metrics"
+)
 
 type ControllerContainer struct {
-	V1 v1
+	V1      v1
+	Metrics *metrics.Metrics
 }
 
 type v1 struct {
-	EndpointController *controllerV1.EndpointController
+	EndpointController     *controllerV1.EndpointController
+	ReportController       *controllerV1.ReportController
+	IncidentController     *controllerV1.IncidentController
+	NotificationController *controllerV1.NotificationController
+	AgentController        *controllerV1.AgentController
+	ManifestController     *controllerV1.ManifestController
+	GroupController        *controllerV1.GroupController
+	StatusController       *controllerV1.StatusController
+	AuthController         *controllerV1.AuthController
+	TeamController         *controllerV1.TeamController
+	ClusterController      *controllerV1.ClusterController
+	ProbeController        *controllerV1.ProbeController
 }
 
-func NewControllerContainer(endpointController *controllerV1.EndpointController) *ControllerContainer {
+func NewControllerContainer(
+	endpointController *controllerV1.EndpointController,
+	reportController *controllerV1.ReportController,
+	incidentController *controllerV1.IncidentController,
+	notificationController *controllerV1.NotificationController,
+	agentController *controllerV1.AgentController,
+	manifestController *controllerV1.ManifestController,
+	groupController *controllerV1.GroupController,
+	statusController *controllerV1.StatusController,
+	authController *controllerV1.AuthController,
+	teamController *controllerV1.TeamController,
+	clusterController *controllerV1.ClusterController,
+	probeController *controllerV1.ProbeController,
+	metrics *metrics.Metrics,
+) *ControllerContainer {
 	return &ControllerContainer{
 		V1: v1{
 			endpointController,
+			reportController,
+			incidentController,
+			notificationController,
+			agentController,
+			manifestController,
+			groupController,
+			statusController,
+			authController,
+			teamController,
+			clusterController,
+			probeController,
 		},
+		Metrics: metrics,
 	}
 }
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgentController struct {
+	endpointService service.EndpointService
+}
+
+func NewAgentController(endpointService service.EndpointService) *AgentController {
+	return &AgentController{endpointService}
+}
+
+func (c *AgentController) FetchAllAgents(ctx *gin.Context) {
+	presenter.Success(ctx, c.endpointService.FetchAllAgents(principalScope(ctx)))
+}
+
+func (c *AgentController) GetAgent(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	agent, err := c.endpointService.GetAgent(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, agent)
+}
@@ -0,0 +1,285 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+var errForbidden = errors.New("insufficient role")
+
+type AuthController struct {
+	authService service.AuthService
+}
+
+func NewAuthController(authService service.AuthService) *AuthController {
+	return &AuthController{authService}
+}
+
+// Authorize rejects requests that are not authenticated with a role granting role. The
+// credential is a bearer token or API key in the Authorization header, or an API key in the
+// X-API-Key header.
+func (c *AuthController) Authorize(role model.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		credential := ctx.GetHeader("X-API-Key")
+		if bearer, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer "); ok {
+			credential = strings.TrimSpace(bearer)
+		}
+
+		principal, err := c.authService.Authenticate(credential)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrUnauthenticated) {
+				status = http.StatusUnauthorized
+			}
+			presenter.Failure(ctx, status, err)
+			ctx.Abort()
+			return
+		}
+		if !principal.Role.Allows(role) {
+			presenter.Failure(ctx, http.StatusForbidden, errForbidden)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(principalKey, principal)
+	}
+}
+
+func currentPrincipal(ctx *gin.Context) *service.Principal {
+	principal, _ := ctx.MustGet(principalKey).(*service.Principal)
+	return principal
+}
+
+// principalScope restricts a request to the team of its principal.
+func principalScope(ctx *gin.Context) repository.Scope {
+	return currentPrincipal(ctx).Scope()
+}
+
+// teamScope restricts a request to the team of the team_id query parameter, which defaults to
+// the team of the principal and can only name another team for operators.
+func teamScope(ctx *gin.Context) (repository.Scope, error) {
+	query := struct {
+		TeamID uint `form:"team_id"`
+	}{}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		return repository.Scope{}, err
+	}
+
+	principal := currentPrincipal(ctx)
+	if query.TeamID == 0 || query.TeamID == principal.TeamID {
+		return principal.Scope(), nil
+	}
+	if !principal.Operator {
+		return repository.Scope{}, errForbidden
+	}
+	return repository.TeamScope(query.TeamID), nil
+}
+
+// AuthorizeOperator rejects requests that are not made by an admin of the default team.
+func (c *AuthController) AuthorizeOperator() gin.HandlerFunc {
+	authorize := c.Authorize(model.RoleAdmin)
+	return func(ctx *gin.Context) {
+		authorize(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+		if !currentPrincipal(ctx).Operator {
+			presenter.Failure(ctx, http.StatusForbidden, errForbidden)
+			ctx.Abort()
+		}
+	}
+}
+
+// AuthorizeProbe rejects requests that are not made by a probe or by an admin of the default
+// team, probes see the endpoints of every team.
+func (c *AuthController) AuthorizeProbe() gin.HandlerFunc {
+	authorize := c.Authorize(model.RoleProbe)
+	return func(ctx *gin.Context) {
+		authorize(ctx)
+		if ctx.IsAborted() {
+			return
+		}
+		principal := currentPrincipal(ctx)
+		if principal.Role != model.RoleProbe && !principal.Operator {
+			presenter.Failure(ctx, http.StatusForbidden, errForbidden)
+			ctx.Abort()
+		}
+	}
+}
+
+func (c *AuthController) Login(ctx *gin.Context) {
+	req := struct {
+		Name     string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	token, expiresAt, err := c.authService.Login(req.Name, req.Password)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			status = http.StatusUnauthorized
+		}
+		presenter.Failure(ctx, status, err)
+		return
+	}
+
+	presenter.Success(ctx, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
+
+func (c *AuthController) Me(ctx *gin.Context) {
+	presenter.Success(ctx, currentPrincipal(ctx))
+}
+
+func (c *AuthController) CreateUser(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	req := struct {
+		Name     string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Role     string `json:"role" binding:"required"`
+	}{}
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := c.authService.CreateUser(scope, req.Name, req.Password, req.Role)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, user)
+}
+
+func (c *AuthController) FetchAllUsers(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	users, err := c.authService.FetchAllUsers(scope)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, users)
+}
+
+func (c *AuthController) DeleteUser(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.authService.DeleteUser(scope, uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "user deleted successfully")
+}
+
+func (c *AuthController) CreateAPIKey(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	req := struct {
+		Name      string     `json:"name" binding:"required"`
+		Role      string     `json:"role" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}{}
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	apiKey, key, err := c.authService.CreateAPIKey(scope, req.Name, req.Role, req.ExpiresAt, currentPrincipal(ctx).Name)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, gin.H{
+		"api_key": apiKey,
+		"key":     key, // only returned here
+	})
+}
+
+func (c *AuthController) FetchAllAPIKeys(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	apiKeys, err := c.authService.FetchAllAPIKeys(scope)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, apiKeys)
+}
+
+func (c *AuthController) RevokeAPIKey(ctx *gin.Context) {
+	scope, err := teamScope(ctx)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.authService.RevokeAPIKey(scope, uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "api key revoked successfully")
+}
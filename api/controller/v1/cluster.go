@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClusterController struct {
+	clusterService service.ClusterService
+}
+
+func NewClusterController(clusterService service.ClusterService) *ClusterController {
+	return &ClusterController{clusterService}
+}
+
+func (c *ClusterController) FetchInstances(ctx *gin.Context) {
+	instances, err := c.clusterService.FetchInstances()
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, gin.H{
+		"instance_id": c.clusterService.InstanceID(), // instance serving the request
+		"cluster":     c.clusterService.Enabled(),
+		"instances":   instances,
+	})
+}
@@ -4,13 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"healthcheck/api/presenter"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
 	"healthcheck/service"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	defaultCheckLogsLimit = 50
+	maxCheckLogsLimit     = 500
+)
+
 type EndpointController struct {
 	endpointService service.EndpointService
 }
@@ -19,37 +27,126 @@ func NewEndpointController(endpointService service.EndpointService) *EndpointCon
 	return &EndpointController{endpointService}
 }
 
-func (c *EndpointController) CreateEndpoint(ctx *gin.Context) {
-	req := struct {
-		URL                string `json:"url" binding:"required"`
-		Interval           int    `json:"interval" binding:"required"`
-		Retries            int    `json:"retries" binding:"required"`
-		HTTPMethod         string `json:"http_method" binding:"required"`
-		HTTPRequestHeaders []struct {
-			Key   string `json:"key"`
-			Value string `json:"value"`
-		} `json:"http_request_headers"`
-		HTTPRequestBody any `json:"http_request_body"`
-	}{}
-	err := ctx.ShouldBindJSON(&req)
+type httpHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type endpointRequest struct {
+	Name               string                 `json:"name"`
+	GroupID            *uint                  `json:"group_id"`
+	CheckType          string                 `json:"check_type"`
+	URL                string                 `json:"url" binding:"required"`
+	Interval           int                    `json:"interval" binding:"required"`
+	Timeout            int                    `json:"timeout"`
+	Retries            int                    `json:"retries" binding:"required"`
+	HTTPMethod         string                 `json:"http_method"`
+	HTTPRequestHeaders []httpHeader           `json:"http_request_headers"`
+	HTTPRequestBody    any                    `json:"http_request_body"`
+	SuccessCriteria    *model.SuccessCriteria `json:"success_criteria"`
+	CheckConfig        *model.CheckConfig     `json:"check_config"`
+	FreshConnection    bool                   `json:"fresh_connection"`
+	Proxy              string                 `json:"proxy"`
+	DNSResolver        string                 `json:"dns_resolver"`
+	RedirectPolicy     string                 `json:"redirect_policy"`
+	MaxRedirects       int                    `json:"max_redirects"`
+	CABundle           string                 `json:"ca_bundle"`
+	ClientCert         string                 `json:"client_cert"`
+	ClientKey          string                 `json:"client_key"`
+	InsecureSkipVerify bool                   `json:"insecure_skip_verify"`
+	Public             bool                   `json:"public"`
+	Locations          []string               `json:"locations"`
+	Quorum             int                    `json:"quorum"`
+}
+
+func (r *endpointRequest) params() (service.EndpointParams, error) {
+	params := service.EndpointParams{
+		Name:               r.Name,
+		GroupID:            r.GroupID,
+		CheckType:          r.CheckType,
+		URL:                r.URL,
+		HTTPMethod:         r.HTTPMethod,
+		Interval:           r.Interval,
+		Timeout:            r.Timeout,
+		Retries:            r.Retries,
+		FreshConnection:    r.FreshConnection,
+		Proxy:              r.Proxy,
+		DNSResolver:        r.DNSResolver,
+		RedirectPolicy:     r.RedirectPolicy,
+		MaxRedirects:       r.MaxRedirects,
+		CABundle:           r.CABundle,
+		ClientCert:         r.ClientCert,
+		ClientKey:          r.ClientKey,
+		InsecureSkipVerify: r.InsecureSkipVerify,
+		Public:             r.Public,
+		Quorum:             r.Quorum,
+	}
+
+	headers, err := json.Marshal(r.HTTPRequestHeaders)
 	if err != nil {
-		presenter.Failure(ctx, http.StatusBadRequest, err)
-		return
+		return params, err
+	}
+	params.HTTPRequestHeaders = string(headers)
+
+	body, err := json.Marshal(r.HTTPRequestBody)
+	if err != nil {
+		return params, err
+	}
+	params.HTTPRequestBody = string(body)
+
+	if r.SuccessCriteria != nil {
+		successCriteria, err := json.Marshal(r.SuccessCriteria)
+		if err != nil {
+			return params, err
+		}
+		params.SuccessCriteria = string(successCriteria)
+	}
+
+	if r.CheckConfig != nil {
+		checkConfig, err := json.Marshal(r.CheckConfig)
+		if err != nil {
+			return params, err
+		}
+		params.CheckConfig = string(checkConfig)
+	}
+
+	locations, err := encodeLocations(r.Locations)
+	if err != nil {
+		return params, err
+	}
+	params.Locations = locations
+
+	return params, nil
+}
+
+// encodeLocations encodes a list of locations for the endpoint params, an empty list is
+// encoded as no locations.
+func encodeLocations(locations []string) (string, error) {
+	if len(locations) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(locations)
+	if err != nil {
+		return "", err
 	}
+	return string(encoded), nil
+}
 
-	headers, err := json.Marshal(req.HTTPRequestHeaders)
+func (c *EndpointController) CreateEndpoint(ctx *gin.Context) {
+	req := endpointRequest{}
+	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
 		presenter.Failure(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	body, err := json.Marshal(req.HTTPRequestBody)
+	params, err := req.params()
 	if err != nil {
 		presenter.Failure(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	err = c.endpointService.CreateEndpoint(req.URL, req.HTTPMethod, string(headers), string(body), req.Interval, req.Retries)
+	_, err = c.endpointService.CreateEndpoint(principalScope(ctx), params)
 	if err != nil {
 		// ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		presenter.Failure(ctx, http.StatusBadRequest, err)
@@ -60,7 +157,7 @@ func (c *EndpointController) CreateEndpoint(ctx *gin.Context) {
 }
 
 func (c *EndpointController) FetchAllEndpoints(ctx *gin.Context) {
-	endpoints, err := c.endpointService.FetchAllEndpoints()
+	endpoints, err := c.endpointService.FetchAllEndpoints(principalScope(ctx))
 	if err != nil {
 		presenter.Failure(ctx, http.StatusBadRequest, err)
 		// ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -70,39 +167,305 @@ func (c *EndpointController) FetchAllEndpoints(ctx *gin.Context) {
 	presenter.Success(ctx, endpoints)
 }
 
-func (c *EndpointController) UpdateEndpointActivationStatus(ctx *gin.Context) {
+func (c *EndpointController) GetEndpoint(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	endpoint, err := c.endpointService.GetEndpoint(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, endpoint)
+}
+
+func (c *EndpointController) UpdateEndpoint(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	req := endpointRequest{}
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	params, err := req.params()
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = c.endpointService.UpdateEndpoint(principalScope(ctx), uint(id), params)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "endpoint updated successfully")
+}
+
+func (c *EndpointController) FetchEndpointCheckLogs(ctx *gin.Context) {
 	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
 	req := struct {
-		Check string `json:"check" binding:"required"`
+		From        *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To          *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+		StatusCodes []int      `form:"status_code"`
+		Location    string     `form:"location"`
+		Cursor      uint       `form:"cursor"`
+		Limit       int        `form:"limit"`
 	}{}
-	err := ctx.ShouldBindJSON(&req)
+	err = ctx.ShouldBindQuery(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultCheckLogsLimit
+	}
+	req.Limit = min(req.Limit, maxCheckLogsLimit)
+
+	checkLogs, err := c.endpointService.FetchEndpointCheckLogs(principalScope(ctx), uint(id), repository.CheckLogFilter{
+		From:        req.From,
+		To:          req.To,
+		StatusCodes: req.StatusCodes,
+		Location:    req.Location,
+		Cursor:      req.Cursor,
+		Limit:       req.Limit,
+	})
 	if err != nil {
 		presenter.Failure(ctx, http.StatusBadRequest, err)
 		return
 	}
+
+	var nextCursor uint
+	if len(checkLogs) == req.Limit {
+		nextCursor = checkLogs[len(checkLogs)-1].ID
+	}
+
+	presenter.Success(ctx, gin.H{
+		"check_logs":  checkLogs,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (c *EndpointController) FetchEndpointLocations(ctx *gin.Context) {
+	idStr := ctx.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil || id <= 0 {
 		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
 		return
 	}
 
-	var status bool
-	switch req.Check {
-	case "activate":
-		status = true
-	case "deactivate":
-		status = false
-	default:
-		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid check"))
+	statuses, err := c.endpointService.FetchEndpointLocations(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
 		return
 	}
 
-	err = c.endpointService.UpdateEndpointActivationStatus(uint(id), status)
+	presenter.Success(ctx, statuses)
+}
+
+// PatchEndpoint partially updates an endpoint's configuration and/or toggles its health check
+// using the "check" field ("activate" or "deactivate").
+func (c *EndpointController) PatchEndpoint(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := struct {
+		Check              *string                `json:"check"`
+		Name               *string                `json:"name"`
+		GroupID            *uint                  `json:"group_id"`
+		CheckType          *string                `json:"check_type"`
+		URL                *string                `json:"url"`
+		Interval           *int                   `json:"interval"`
+		Timeout            *int                   `json:"timeout"`
+		Retries            *int                   `json:"retries"`
+		HTTPMethod         *string                `json:"http_method"`
+		HTTPRequestHeaders *[]httpHeader          `json:"http_request_headers"`
+		HTTPRequestBody    any                    `json:"http_request_body"`
+		SuccessCriteria    *model.SuccessCriteria `json:"success_criteria"`
+		CheckConfig        *model.CheckConfig     `json:"check_config"`
+		FreshConnection    *bool                  `json:"fresh_connection"`
+		Proxy              *string                `json:"proxy"`
+		DNSResolver        *string                `json:"dns_resolver"`
+		RedirectPolicy     *string                `json:"redirect_policy"`
+		MaxRedirects       *int                   `json:"max_redirects"`
+		CABundle           *string                `json:"ca_bundle"`
+		ClientCert         *string                `json:"client_cert"`
+		ClientKey          *string                `json:"client_key"`
+		InsecureSkipVerify *bool                  `json:"insecure_skip_verify"`
+		Public             *bool                  `json:"public"`
+		Locations          *[]string              `json:"locations"`
+		Quorum             *int                   `json:"quorum"`
+	}{}
+	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
 		presenter.Failure(ctx, http.StatusBadRequest, err)
-		// ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	var status bool
+	if req.Check != nil {
+		switch *req.Check {
+		case "activate":
+			status = true
+		case "deactivate":
+			status = false
+		default:
+			presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid check"))
+			return
+		}
+	}
+
+	if req.Name != nil || req.GroupID != nil || req.CheckType != nil || req.URL != nil || req.Interval != nil || req.Timeout != nil || req.Retries != nil || req.HTTPMethod != nil ||
+		req.HTTPRequestHeaders != nil || req.HTTPRequestBody != nil || req.SuccessCriteria != nil || req.CheckConfig != nil ||
+		req.FreshConnection != nil || req.Proxy != nil || req.DNSResolver != nil ||
+		req.RedirectPolicy != nil || req.MaxRedirects != nil || req.CABundle != nil ||
+		req.ClientCert != nil || req.ClientKey != nil || req.InsecureSkipVerify != nil || req.Public != nil ||
+		req.Locations != nil || req.Quorum != nil {
+		endpoint, err := c.endpointService.GetEndpoint(principalScope(ctx), uint(id))
+		if err != nil {
+			presenter.Failure(ctx, failureStatusCode(err), err)
+			return
+		}
+
+		params := service.NewEndpointParams(endpoint)
+		if req.Name != nil {
+			params.Name = *req.Name
+		}
+		if req.GroupID != nil {
+			// 0 removes the endpoint from its group
+			params.GroupID = req.GroupID
+			if *req.GroupID == 0 {
+				params.GroupID = nil
+			}
+		}
+		if req.CheckType != nil {
+			params.CheckType = *req.CheckType
+		}
+		if req.URL != nil {
+			params.URL = *req.URL
+		}
+		if req.Interval != nil {
+			params.Interval = *req.Interval
+		}
+		if req.Timeout != nil {
+			params.Timeout = *req.Timeout
+		}
+		if req.Retries != nil {
+			params.Retries = *req.Retries
+		}
+		if req.HTTPMethod != nil {
+			params.HTTPMethod = *req.HTTPMethod
+		}
+		if req.HTTPRequestHeaders != nil {
+			headers, err := json.Marshal(*req.HTTPRequestHeaders)
+			if err != nil {
+				presenter.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			params.HTTPRequestHeaders = string(headers)
+		}
+		if req.HTTPRequestBody != nil {
+			body, err := json.Marshal(req.HTTPRequestBody)
+			if err != nil {
+				presenter.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			params.HTTPRequestBody = string(body)
+		}
+		if req.SuccessCriteria != nil {
+			successCriteria, err := json.Marshal(req.SuccessCriteria)
+			if err != nil {
+				presenter.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			params.SuccessCriteria = string(successCriteria)
+		}
+		if req.CheckConfig != nil {
+			checkConfig, err := json.Marshal(req.CheckConfig)
+			if err != nil {
+				presenter.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			params.CheckConfig = string(checkConfig)
+		}
+		if req.FreshConnection != nil {
+			params.FreshConnection = *req.FreshConnection
+		}
+		if req.Proxy != nil {
+			params.Proxy = *req.Proxy
+		}
+		if req.DNSResolver != nil {
+			params.DNSResolver = *req.DNSResolver
+		}
+		if req.RedirectPolicy != nil {
+			params.RedirectPolicy = *req.RedirectPolicy
+		}
+		if req.MaxRedirects != nil {
+			params.MaxRedirects = *req.MaxRedirects
+		}
+		if req.CABundle != nil {
+			params.CABundle = *req.CABundle
+		}
+		if req.ClientCert != nil {
+			params.ClientCert = *req.ClientCert
+		}
+		if req.ClientKey != nil {
+			params.ClientKey = *req.ClientKey
+		}
+		if req.InsecureSkipVerify != nil {
+			params.InsecureSkipVerify = *req.InsecureSkipVerify
+		}
+		if req.Public != nil {
+			params.Public = *req.Public
+		}
+		if req.Locations != nil {
+			locations, err := encodeLocations(*req.Locations)
+			if err != nil {
+				presenter.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			params.Locations = locations
+		}
+		if req.Quorum != nil {
+			params.Quorum = *req.Quorum
+		}
+
+		err = c.endpointService.UpdateEndpoint(principalScope(ctx), uint(id), params)
+		if err != nil {
+			presenter.Failure(ctx, failureStatusCode(err), err)
+			return
+		}
+	}
+
+	if req.Check != nil {
+		err = c.endpointService.UpdateEndpointActivationStatus(principalScope(ctx), uint(id), status)
+		if err != nil {
+			presenter.Failure(ctx, http.StatusBadRequest, err)
+			// ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	presenter.Success(ctx, "endpoint updated successfully")
 }
@@ -115,7 +478,7 @@ func (c *EndpointController) DeleteEndpoint(ctx *gin.Context) {
 		return
 	}
 
-	err = c.endpointService.DeleteEndpoint(uint(id))
+	err = c.endpointService.DeleteEndpoint(principalScope(ctx), uint(id))
 	if err != nil {
 		// ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		presenter.Failure(ctx, http.StatusBadRequest, err)
@@ -124,3 +487,13 @@ func (c *EndpointController) DeleteEndpoint(ctx *gin.Context) {
 
 	presenter.Success(ctx, "endpoint deleted successfully")
 }
+
+func failureStatusCode(err error) int {
+	if errors.Is(err, repository.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, errForbidden) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupController struct {
+	groupService service.EndpointGroupService
+}
+
+func NewGroupController(groupService service.EndpointGroupService) *GroupController {
+	return &GroupController{groupService}
+}
+
+type groupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	req := groupRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	group, err := c.groupService.CreateGroup(principalScope(ctx), req.Name, req.Description)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, group)
+}
+
+func (c *GroupController) FetchAllGroups(ctx *gin.Context) {
+	groups, err := c.groupService.FetchAllGroups(principalScope(ctx))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, groups)
+}
+
+func (c *GroupController) GetGroup(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	group, err := c.groupService.GetGroup(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, group)
+}
+
+func (c *GroupController) UpdateGroup(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := groupRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.groupService.UpdateGroup(principalScope(ctx), uint(id), req.Name, req.Description)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "group updated successfully")
+}
+
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.groupService.DeleteGroup(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "group deleted successfully")
+}
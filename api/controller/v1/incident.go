@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/internal/repository"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultIncidentsLimit = 50
+	maxIncidentsLimit     = 500
+)
+
+type IncidentController struct {
+	incidentService service.IncidentService
+}
+
+func NewIncidentController(incidentService service.IncidentService) *IncidentController {
+	return &IncidentController{incidentService}
+}
+
+func (c *IncidentController) FetchIncidents(ctx *gin.Context) {
+	c.fetchIncidents(ctx, 0)
+}
+
+func (c *IncidentController) FetchEndpointIncidents(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	c.fetchIncidents(ctx, uint(id))
+}
+
+func (c *IncidentController) fetchIncidents(ctx *gin.Context, endpointID uint) {
+	req := struct {
+		EndpointID uint   `form:"endpoint_id"`
+		Status     string `form:"status"`
+		Cursor     uint   `form:"cursor"`
+		Limit      int    `form:"limit"`
+	}{}
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > maxIncidentsLimit {
+		req.Limit = defaultIncidentsLimit
+	}
+	if endpointID > 0 {
+		req.EndpointID = endpointID
+	}
+
+	filter := repository.IncidentFilter{
+		EndpointID: req.EndpointID,
+		Cursor:     req.Cursor,
+		Limit:      req.Limit,
+	}
+	switch req.Status {
+	case "":
+	case "open":
+		open := true
+		filter.Open = &open
+	case "resolved":
+		open := false
+		filter.Open = &open
+	default:
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid status"))
+		return
+	}
+
+	incidents, err := c.incidentService.FetchIncidents(principalScope(ctx), filter)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var nextCursor uint
+	if len(incidents) == req.Limit {
+		nextCursor = incidents[len(incidents)-1].ID
+	}
+
+	presenter.Success(ctx, gin.H{
+		"incidents":   incidents,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (c *IncidentController) GetIncident(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	incident, err := c.incidentService.GetIncident(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, incident)
+}
+
+func (c *IncidentController) AcknowledgeIncident(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := struct {
+		By string `json:"by" binding:"required"`
+	}{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.incidentService.AcknowledgeIncident(principalScope(ctx), uint(id), req.By)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "incident acknowledged successfully")
+}
+
+func (c *IncidentController) AnnotateIncident(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := struct {
+		Author string `json:"author" binding:"required"`
+		Text   string `json:"text" binding:"required"`
+	}{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.incidentService.AnnotateIncident(principalScope(ctx), uint(id), req.Author, req.Text)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "incident annotated successfully")
+}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ManifestController struct {
+	manifestService service.ManifestService
+}
+
+func NewManifestController(manifestService service.ManifestService) *ManifestController {
+	return &ManifestController{manifestService}
+}
+
+func (c *ManifestController) ApplyManifest(ctx *gin.Context) {
+	query := struct {
+		Prune bool `form:"prune"`
+	}{}
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := ctx.GetRawData()
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	manifest, err := service.ParseManifest(data)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := c.manifestService.ApplyManifest(principalScope(ctx), manifest, query.Prune)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, report)
+}
+
+func (c *ManifestController) ExportManifest(ctx *gin.Context) {
+	query := struct {
+		Format string `form:"format"`
+	}{}
+	err := ctx.ShouldBindQuery(&query)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	contentType := "application/yaml"
+	switch query.Format {
+	case "", "yaml":
+		query.Format = "yaml"
+	case "json":
+		contentType = "application/json"
+	default:
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("format must be yaml or json"))
+		return
+	}
+
+	manifest, err := c.manifestService.ExportManifest(principalScope(ctx))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := service.EncodeManifest(manifest, query.Format)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, data)
+}
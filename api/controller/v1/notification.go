@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type NotificationController struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationController(notificationService service.NotificationService) *NotificationController {
+	return &NotificationController{notificationService}
+}
+
+type channelRequest struct {
+	Name    string              `json:"name" binding:"required"`
+	Type    string              `json:"type" binding:"required"`
+	Config  model.ChannelConfig `json:"config"`
+	Enabled *bool               `json:"enabled"`
+}
+
+func (c *NotificationController) CreateChannel(ctx *gin.Context) {
+	req := channelRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	config, err := json.Marshal(req.Config)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = c.notificationService.CreateChannel(principalScope(ctx), req.Name, req.Type, string(config))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, "channel registered successfully")
+}
+
+func (c *NotificationController) FetchAllChannels(ctx *gin.Context) {
+	channels, err := c.notificationService.FetchAllChannels(principalScope(ctx))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, channels)
+}
+
+func (c *NotificationController) GetChannel(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	channel, err := c.notificationService.GetChannel(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, channel)
+}
+
+func (c *NotificationController) UpdateChannel(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := channelRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	config, err := json.Marshal(req.Config)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	err = c.notificationService.UpdateChannel(principalScope(ctx), uint(id), req.Name, req.Type, string(config), enabled)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "channel updated successfully")
+}
+
+func (c *NotificationController) DeleteChannel(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.notificationService.DeleteChannel(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, "channel deleted successfully")
+}
+
+func (c *NotificationController) FetchEndpointChannels(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	channels, err := c.notificationService.FetchEndpointChannels(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, channels)
+}
+
+func (c *NotificationController) Subscribe(ctx *gin.Context) {
+	endpointID, channelID, err := subscriptionParams(ctx)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = c.notificationService.Subscribe(principalScope(ctx), endpointID, channelID)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "endpoint subscribed successfully")
+}
+
+func (c *NotificationController) Unsubscribe(ctx *gin.Context) {
+	endpointID, channelID, err := subscriptionParams(ctx)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = c.notificationService.Unsubscribe(principalScope(ctx), endpointID, channelID)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, "endpoint unsubscribed successfully")
+}
+
+func (c *NotificationController) FetchDeliveries(ctx *gin.Context) {
+	req := struct {
+		Status     string `form:"status"`
+		EndpointID uint   `form:"endpoint_id"`
+		Cursor     uint   `form:"cursor"`
+		Limit      int    `form:"limit"`
+	}{}
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Status != "" {
+		if err := model.DeliveryStatus(req.Status).Validate(); err != nil {
+			presenter.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if req.Limit <= 0 || req.Limit > maxDeliveriesLimit {
+		req.Limit = defaultDeliveriesLimit
+	}
+
+	deliveries, err := c.notificationService.FetchDeliveries(principalScope(ctx), repository.DeliveryFilter{
+		Status:     model.DeliveryStatus(req.Status),
+		EndpointID: req.EndpointID,
+		Cursor:     req.Cursor,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var nextCursor uint
+	if len(deliveries) == req.Limit {
+		nextCursor = deliveries[len(deliveries)-1].ID
+	}
+
+	presenter.Success(ctx, gin.H{
+		"deliveries":  deliveries,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (c *NotificationController) GetDelivery(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	delivery, err := c.notificationService.GetDelivery(principalScope(ctx), uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, delivery)
+}
+
+func (c *NotificationController) Redeliver(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.notificationService.Redeliver(principalScope(ctx), uint(id))
+	if err != nil {
+		status := failureStatusCode(err)
+		if errors.Is(err, service.ErrDeliveryNotDead) {
+			status = http.StatusConflict
+		}
+		presenter.Failure(ctx, status, err)
+		return
+	}
+
+	presenter.Success(ctx, "delivery queued successfully")
+}
+
+func subscriptionParams(ctx *gin.Context) (endpointID, channelID uint, err error) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		return 0, 0, errors.New("invalid id")
+	}
+	cid, err := strconv.Atoi(ctx.Param("channel_id"))
+	if err != nil || cid <= 0 {
+		return 0, 0, errors.New("invalid channel id")
+	}
+	return uint(id), uint(cid), nil
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"healthcheck/api/presenter"
+	"healthcheck/internal/model"
+	"healthcheck/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProbeController struct {
+	probeService service.ProbeService
+}
+
+func NewProbeController(probeService service.ProbeService) *ProbeController {
+	return &ProbeController{probeService}
+}
+
+func (c *ProbeController) FetchChecks(ctx *gin.Context) {
+	query := struct {
+		Location string `form:"location" binding:"required"`
+	}{}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	assignments, err := c.probeService.FetchAssignments(query.Location)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, assignments)
+}
+
+func (c *ProbeController) RecordResults(ctx *gin.Context) {
+	req := struct {
+		Location string            `json:"location" binding:"required"`
+		Results  []*model.CheckLog `json:"results"`
+	}{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Results) > service.MaxProbeBatch {
+		presenter.Failure(ctx, http.StatusBadRequest, fmt.Errorf("at most %d results per request", service.MaxProbeBatch))
+		return
+	}
+	for _, result := range req.Results {
+		if result == nil {
+			presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid result"))
+			return
+		}
+	}
+
+	accepted, err := c.probeService.RecordResults(req.Location, req.Results)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, gin.H{"accepted": accepted})
+}
@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/internal/model"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultStatsWindow = 24 * time.Hour
+
+type ReportController struct {
+	reportService service.ReportService
+}
+
+func NewReportController(reportService service.ReportService) *ReportController {
+	return &ReportController{reportService}
+}
+
+func (c *ReportController) FetchEndpointStats(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	from, to, err := statsWindow(ctx)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := c.reportService.EndpointStats(principalScope(ctx), uint(id), from, to)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, stats)
+}
+
+func (c *ReportController) FetchEndpointRollups(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	req := struct {
+		Resolution string `form:"resolution"`
+		Location   string `form:"location"`
+	}{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Resolution == "" {
+		req.Resolution = string(model.RollupHourly)
+	}
+	from, to, err := statsWindow(ctx)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rollups, err := c.reportService.EndpointRollups(principalScope(ctx), uint(id), model.RollupResolution(req.Resolution), req.Location, from, to)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, rollups)
+}
+
+func (c *ReportController) FetchStats(ctx *gin.Context) {
+	from, to, err := statsWindow(ctx)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := c.reportService.AggregateStats(principalScope(ctx), from, to)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, stats)
+}
+
+// statsWindow reads either a custom from/to range or a window such as "24h", "7d" or "30d"
+// ending now from the query string.
+func statsWindow(ctx *gin.Context) (from, to time.Time, err error) {
+	req := struct {
+		Window string     `form:"window"`
+		From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	}{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return from, to, err
+	}
+
+	to = time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	if req.From != nil {
+		from = *req.From
+	} else {
+		window := defaultStatsWindow
+		if req.Window != "" {
+			window, err = parseWindow(req.Window)
+			if err != nil {
+				return from, to, err
+			}
+		}
+		from = to.Add(-window)
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("invalid window")
+	}
+	return from, to, nil
+}
+
+func parseWindow(window string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("invalid window")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return 0, errors.New("invalid window")
+	}
+	return d, nil
+}
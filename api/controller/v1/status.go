@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/status.html
+var templates embed.FS
+
+var statusPage = template.Must(template.New("status.html").Funcs(template.FuncMap{
+	"uptime": func(uptime *float64) string {
+		if uptime == nil {
+			return "no data"
+		}
+		return fmt.Sprintf("%.2f%%", *uptime)
+	},
+	"bar": func(uptime *float64) string {
+		switch {
+		case uptime == nil:
+			return "none"
+		case *uptime >= 99.9:
+			return "good"
+		case *uptime >= 99:
+			return "fair"
+		case *uptime >= 95:
+			return "poor"
+		default:
+			return "bad"
+		}
+	},
+}).ParseFS(templates, "templates/status.html"))
+
+// StatusController serves the public status page, it only shows public endpoints.
+type StatusController struct {
+	statusService service.StatusService
+}
+
+func NewStatusController(statusService service.StatusService) *StatusController {
+	return &StatusController{statusService}
+}
+
+func (c *StatusController) StatusSummary(ctx *gin.Context) {
+	summary, err := c.statusService.Summary()
+	if err != nil {
+		presenter.Failure(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	presenter.Success(ctx, summary)
+}
+
+func (c *StatusController) StatusPage(ctx *gin.Context) {
+	summary, err := c.statusService.Summary()
+	if err != nil {
+		log.Println("failed to build status summary, err:", err.Error())
+		ctx.String(http.StatusInternalServerError, "status is unavailable")
+		return
+	}
+
+	page := bytes.Buffer{}
+	if err := statusPage.Execute(&page, summary); err != nil {
+		log.Println("failed to render status page, err:", err.Error())
+		ctx.String(http.StatusInternalServerError, "status is unavailable")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
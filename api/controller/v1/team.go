@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"healthcheck/api/presenter"
+	"healthcheck/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamController struct {
+	teamService service.TeamService
+}
+
+func NewTeamController(teamService service.TeamService) *TeamController {
+	return &TeamController{teamService}
+}
+
+type teamRequest struct {
+	Name         string `json:"name" binding:"required"`
+	MaxEndpoints int    `json:"max_endpoints"`
+	MinInterval  int    `json:"min_interval"`
+}
+
+func (c *TeamController) CreateTeam(ctx *gin.Context) {
+	req := teamRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	team, err := c.teamService.CreateTeam(req.Name, req.MaxEndpoints, req.MinInterval)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, team)
+}
+
+func (c *TeamController) FetchAllTeams(ctx *gin.Context) {
+	teams, err := c.teamService.FetchAllTeams()
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	presenter.Success(ctx, teams)
+}
+
+func (c *TeamController) GetTeam(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	team, err := c.teamService.GetTeam(uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, team)
+}
+
+// GetCurrentTeam returns the team of the principal, with its quotas.
+func (c *TeamController) GetCurrentTeam(ctx *gin.Context) {
+	team, err := c.teamService.GetTeam(currentPrincipal(ctx).TeamID)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, team)
+}
+
+func (c *TeamController) UpdateTeam(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	req := teamRequest{}
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		presenter.Failure(ctx, http.StatusBadRequest, err)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.teamService.UpdateTeam(uint(id), req.Name, req.MaxEndpoints, req.MinInterval)
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "team updated successfully")
+}
+
+func (c *TeamController) DeleteTeam(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		presenter.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+		return
+	}
+
+	err = c.teamService.DeleteTeam(uint(id))
+	if err != nil {
+		presenter.Failure(ctx, failureStatusCode(err), err)
+		return
+	}
+
+	presenter.Success(ctx, "team deleted successfully")
+}
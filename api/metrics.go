@@ -0,0 +1,22 @@
+package api
+
+import (
+	"healthcheck/pkg/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// observeRequests records the method, route and status of every request served.
+func observeRequests(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTPRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
@@ -1,20 +1,127 @@
 package api
 
-import "github.com/gin-gonic/gin"
+import (
+	"healthcheck/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
 
 func SetupRoutes(container *ControllerContainer) *gin.Engine {
+	viewer := container.V1.AuthController.Authorize(model.RoleViewer)
+	editor := container.V1.AuthController.Authorize(model.RoleEditor)
+	admin := container.V1.AuthController.Authorize(model.RoleAdmin)
+	operator := container.V1.AuthController.AuthorizeOperator()
+	probe := container.V1.AuthController.AuthorizeProbe()
+
 	routes := gin.Default()
+	routes.Use(observeRequests(container.Metrics))
+	routes.GET("/metrics", operator, gin.WrapH(container.Metrics.Handler()))
+	routes.GET("/status", container.V1.StatusController.StatusPage)
+
 	api := routes.Group("/api")
 	{
 		v1 := api.Group("/v1")
 		{
+			auth := v1.Group("/auth")
+			{
+				auth.POST("/token", container.V1.AuthController.Login)
+				auth.GET("/me", viewer, container.V1.AuthController.Me)
+			}
+
+			users := v1.Group("/users", admin)
+			{
+				users.POST("/", container.V1.AuthController.CreateUser)
+				users.GET("/", container.V1.AuthController.FetchAllUsers)
+				users.DELETE("/:id", container.V1.AuthController.DeleteUser)
+			}
+
+			keys := v1.Group("/keys", admin)
+			{
+				keys.POST("/", container.V1.AuthController.CreateAPIKey)
+				keys.GET("/", container.V1.AuthController.FetchAllAPIKeys)
+				keys.DELETE("/:id", container.V1.AuthController.RevokeAPIKey)
+			}
+
+			v1.GET("/team", viewer, container.V1.TeamController.GetCurrentTeam)
+			teams := v1.Group("/teams", operator)
+			{
+				teams.POST("/", container.V1.TeamController.CreateTeam)
+				teams.GET("/", container.V1.TeamController.FetchAllTeams)
+				teams.GET("/:id", container.V1.TeamController.GetTeam)
+				teams.PUT("/:id", container.V1.TeamController.UpdateTeam)
+				teams.DELETE("/:id", container.V1.TeamController.DeleteTeam)
+			}
+
+			v1.GET("/cluster", operator, container.V1.ClusterController.FetchInstances)
+
+			probes := v1.Group("/probes", probe)
+			{
+				probes.GET("/checks", container.V1.ProbeController.FetchChecks)
+				probes.POST("/results", container.V1.ProbeController.RecordResults)
+			}
+
 			endpoints := v1.Group("/endpoints")
 			{
-				endpoints.POST("/", container.V1.EndpointController.CreateEndpoint)
-				endpoints.GET("/", container.V1.EndpointController.FetchAllEndpoints)
-				endpoints.PATCH("/:id", container.V1.EndpointController.UpdateEndpointActivationStatus)
-				endpoints.DELETE("/:id", container.V1.EndpointController.DeleteEndpoint)
+				endpoints.POST("/", editor, container.V1.EndpointController.CreateEndpoint)
+				endpoints.GET("/", viewer, container.V1.EndpointController.FetchAllEndpoints)
+				endpoints.GET("/:id", viewer, container.V1.EndpointController.GetEndpoint)
+				endpoints.GET("/:id/logs", viewer, container.V1.EndpointController.FetchEndpointCheckLogs)
+				endpoints.GET("/:id/locations", viewer, container.V1.EndpointController.FetchEndpointLocations)
+				endpoints.GET("/:id/stats", viewer, container.V1.ReportController.FetchEndpointStats)
+				endpoints.GET("/:id/rollups", viewer, container.V1.ReportController.FetchEndpointRollups)
+				endpoints.GET("/:id/incidents", viewer, container.V1.IncidentController.FetchEndpointIncidents)
+				endpoints.GET("/:id/channels", viewer, container.V1.NotificationController.FetchEndpointChannels)
+				endpoints.POST("/:id/channels/:channel_id", editor, container.V1.NotificationController.Subscribe)
+				endpoints.DELETE("/:id/channels/:channel_id", editor, container.V1.NotificationController.Unsubscribe)
+				endpoints.PUT("/:id", editor, container.V1.EndpointController.UpdateEndpoint)
+				endpoints.PATCH("/:id", editor, container.V1.EndpointController.PatchEndpoint)
+				endpoints.DELETE("/:id", editor, container.V1.EndpointController.DeleteEndpoint)
+			}
+
+			groups := v1.Group("/groups")
+			{
+				groups.POST("/", editor, container.V1.GroupController.CreateGroup)
+				groups.GET("/", viewer, container.V1.GroupController.FetchAllGroups)
+				groups.GET("/:id", viewer, container.V1.GroupController.GetGroup)
+				groups.PUT("/:id", editor, container.V1.GroupController.UpdateGroup)
+				groups.DELETE("/:id", editor, container.V1.GroupController.DeleteGroup)
+			}
+
+			agents := v1.Group("/agents")
+			{
+				agents.GET("/", viewer, container.V1.AgentController.FetchAllAgents)
+				agents.GET("/:id", viewer, container.V1.AgentController.GetAgent)
+			}
+
+			channels := v1.Group("/channels")
+			{
+				channels.POST("/", editor, container.V1.NotificationController.CreateChannel)
+				channels.GET("/", viewer, container.V1.NotificationController.FetchAllChannels)
+				channels.GET("/:id", viewer, container.V1.NotificationController.GetChannel)
+				channels.PUT("/:id", editor, container.V1.NotificationController.UpdateChannel)
+				channels.DELETE("/:id", editor, container.V1.NotificationController.DeleteChannel)
 			}
+
+			deliveries := v1.Group("/deliveries")
+			{
+				deliveries.GET("/", viewer, container.V1.NotificationController.FetchDeliveries)
+				deliveries.GET("/:id", viewer, container.V1.NotificationController.GetDelivery)
+				deliveries.POST("/:id/redeliver", editor, container.V1.NotificationController.Redeliver)
+			}
+
+			incidents := v1.Group("/incidents")
+			{
+				incidents.GET("/", viewer, container.V1.IncidentController.FetchIncidents)
+				incidents.GET("/:id", viewer, container.V1.IncidentController.GetIncident)
+				incidents.POST("/:id/acknowledge", editor, container.V1.IncidentController.AcknowledgeIncident)
+				incidents.POST("/:id/annotations", editor, container.V1.IncidentController.AnnotateIncident)
+			}
+
+			v1.POST("/manifest", editor, container.V1.ManifestController.ApplyManifest)
+			v1.GET("/manifest", editor, container.V1.ManifestController.ExportManifest)
+
+			v1.GET("/status", container.V1.StatusController.StatusSummary)
+			v1.GET("/stats", viewer, container.V1.ReportController.FetchStats)
 		}
 	}
 
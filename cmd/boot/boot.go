@@ -1,17 +1,23 @@
 package boot
 
 import (
+	"context"
 	"fmt"
 	"healthcheck/api"
 	"healthcheck/config"
 	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
 	"healthcheck/pkg/postgres"
+	"healthcheck/pkg/scheduler"
+	"healthcheck/service"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+
+	"gorm.io/gorm"
 )
 
 func Up(cfg *config.Config) (map[string]func(), *sync.WaitGroup, error) {
@@ -28,7 +34,42 @@ func Up(cfg *config.Config) (map[string]func(), *sync.WaitGroup, error) {
 	}
 	closeFunctions["db"] = func() { postgres.Disconnect(db) }
 
-	if err := db.AutoMigrate(&model.Endpoint{}, &model.CheckLog{}); err != nil {
+	// instances starting together migrate, create the default team and admin and apply the
+	// manifest one after the other
+	unlock, err := repository.NewInstanceRepository(db).Lock("startup")
+	if err != nil {
+		log.Println("failed to take the startup lock, err:", err.Error())
+		return closeFunctions, nil, err
+	}
+	defer unlock()
+
+	if err := db.AutoMigrate(
+		&model.Team{},
+		&model.EndpointGroup{},
+		&model.Endpoint{},
+		&model.CheckLog{},
+		&model.TLSCertificate{},
+		&model.Incident{},
+		&model.IncidentAnnotation{},
+		&model.NotificationChannel{},
+		&model.NotificationSubscription{},
+		&model.NotificationDelivery{},
+		&model.DeliveryAttempt{},
+		&model.User{},
+		&model.APIKey{},
+		&model.Instance{},
+		&model.LocationStatus{},
+		&model.HourlyCheckRollup{},
+		&model.DailyCheckRollup{},
+	); err != nil {
+		log.Println("db migration failed, err:", err.Error())
+		return closeFunctions, nil, err
+	}
+	if err := dropGlobalNameIndexes(db); err != nil {
+		log.Println("db migration failed, err:", err.Error())
+		return closeFunctions, nil, err
+	}
+	if err := createCheckLogIndexes(db); err != nil {
 		log.Println("db migration failed, err:", err.Error())
 		return closeFunctions, nil, err
 	}
@@ -39,6 +80,7 @@ func Up(cfg *config.Config) (map[string]func(), *sync.WaitGroup, error) {
 		log.Println("dependency injection failed, err:", err.Error())
 		return closeFunctions, nil, err
 	}
+	unlock()
 
 	router := api.SetupRoutes(container)
 
@@ -65,6 +107,43 @@ func Up(cfg *config.Config) (map[string]func(), *sync.WaitGroup, error) {
 	return closeFunctions, wg, nil
 }
 
+// Probe runs the checks assigned to the location of the probe by its server, without a
+// database nor an API.
+func Probe(cfg *config.Config) (map[string]func(), *sync.WaitGroup, error) {
+	closeFunctions := make(map[string]func())
+
+	wg := &sync.WaitGroup{}
+	checkScheduler := scheduler.New(cfg.Scheduler.Workers)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	checkScheduler.Run(schedulerCtx, wg)
+	closeFunctions["scheduler"] = stopScheduler
+	httpClient := newHTTPClient(cfg)
+	closeFunctions["httpClient"] = httpClient.Close
+
+	probeWorker, err := service.NewProbeWorker(
+		cfg.Location,
+		cfg.Probe.ServerURL,
+		cfg.Probe.Token,
+		cfg.Probe.SyncInterval,
+		httpClient,
+		newCheckTimeouts(cfg),
+		repository.NewAgentInMemoryRepository(checkScheduler),
+	)
+	if err != nil {
+		log.Println("probe failed to start, err:", err.Error())
+		return closeFunctions, wg, err
+	}
+	closeFunctions["probeWorker"] = probeWorker.Shutdown
+	log.Println("probe started for location", cfg.Location)
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+	<-shutdown
+	log.Println("shutdown signal received")
+
+	return closeFunctions, wg, nil
+}
+
 func Down(closeFunctions map[string]func(), wg *sync.WaitGroup) {
 	for key, fn := range closeFunctions {
 		if key != "db" {
@@ -72,7 +151,48 @@ func Down(closeFunctions map[string]func(), wg *sync.WaitGroup) {
 			log.Println(key, "closed")
 		}
 	}
-	wg.Wait()
-	closeFunctions["db"]()
-	log.Println("db", "closed")
+	if wg != nil {
+		wg.Wait()
+	}
+	if closeDB, ok := closeFunctions["db"]; ok {
+		closeDB()
+		log.Println("db", "closed")
+	}
+}
+
+// dropGlobalNameIndexes drops the unique name indexes that predate teams, names are now unique
+// within a team only.
+func dropGlobalNameIndexes(db *gorm.DB) error {
+	indexes := map[string]any{
+		"idx_endpoint_groups_name":       &model.EndpointGroup{},
+		"idx_notification_channels_name": &model.NotificationChannel{},
+	}
+	for name, m := range indexes {
+		if !db.Migrator().HasIndex(m, name) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(m, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createCheckLogIndexes indexes the check logs on columns that come from gorm.Model and cannot
+// be tagged: by endpoint and id for the paginated logs of an endpoint and the reports, and by
+// creation time for the rollups and the pruning of old check logs.
+func createCheckLogIndexes(db *gorm.DB) error {
+	indexes := []struct{ name, columns string }{
+		{"idx_check_logs_endpoint_id_id", "endpoint_id, id"},
+		{"idx_check_logs_created_at", "created_at"},
+	}
+	for _, index := range indexes {
+		if db.Migrator().HasIndex(&model.CheckLog{}, index.name) {
+			continue
+		}
+		if err := db.Exec("CREATE INDEX " + index.name + " ON check_logs (" + index.columns + ")").Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -1,10 +1,14 @@
 package boot
 
 import (
+	"context"
 	"healthcheck/api"
 	controllerV1 "healthcheck/api/controller/v1"
 	"healthcheck/config"
 	"healthcheck/internal/repository"
+	httpclient "healthcheck/pkg/http_client"
+	"healthcheck/pkg/metrics"
+	"healthcheck/pkg/scheduler"
 	"healthcheck/service"
 	"sync"
 
@@ -16,17 +20,170 @@ func Inject(db *gorm.DB, wg *sync.WaitGroup, cfg *config.Config, closeFunctions
 	// Repositories
 	endpointRepo := repository.NewEndpointRepository(db)
 	checkLogRepo := repository.NewCheckLogRepository(db)
-	healthCheckAgentRepo := repository.NewAgentInMemoryRepository()
+	incidentRepo := repository.NewIncidentRepository(db)
+	certificateRepo := repository.NewTLSCertificateRepository(db)
+	notificationChannelRepo := repository.NewNotificationChannelRepository(db)
+	notificationDeliveryRepo := repository.NewNotificationDeliveryRepository(db)
+	endpointGroupRepo := repository.NewEndpointGroupRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	instanceRepo := repository.NewInstanceRepository(db)
+	locationStatusRepo := repository.NewLocationStatusRepository(db)
+	checkRollupRepo := repository.NewCheckRollupRepository(db)
+	checkScheduler := scheduler.New(cfg.Scheduler.Workers)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	checkScheduler.Run(schedulerCtx, wg)
+	closeFunctions["scheduler"] = stopScheduler
+	healthCheckAgentRepo := repository.NewAgentInMemoryRepository(checkScheduler)
 
 	// Services
-	endpointService, err := service.NewEndpointService(cfg.WebhookURL, wg, checkLogRepo, endpointRepo, healthCheckAgentRepo)
+	clusterService, err := service.NewClusterService(
+		cfg.Cluster.Enabled,
+		cfg.Cluster.InstanceID,
+		cfg.Cluster.HeartbeatInterval,
+		cfg.Cluster.InstanceTTL,
+		wg,
+		instanceRepo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	closeFunctions["clusterService"] = clusterService.Shutdown
+	teamService, err := service.NewTeamService(cfg.DefaultTeam, teamRepo, endpointRepo, userRepo, apiKeyRepo)
+	if err != nil {
+		return nil, err
+	}
+	defaultScope := repository.TeamScope(teamService.DefaultTeamID())
+	serviceMetrics := metrics.New()
+	deliveryPolicy := service.DeliveryPolicy{
+		MaxAttempts: cfg.Delivery.MaxAttempts,
+		BaseBackoff: cfg.Delivery.BaseBackoff,
+		MaxBackoff:  cfg.Delivery.MaxBackoff,
+	}
+	notificationService := service.NewNotificationService(
+		cfg.WebhookURL,
+		cfg.WebhookSecret,
+		deliveryPolicy,
+		wg,
+		serviceMetrics,
+		notificationChannelRepo,
+		notificationDeliveryRepo,
+		endpointRepo,
+	)
+	closeFunctions["notificationService"] = func() { notificationService.Shutdown() }
+	httpClient := newHTTPClient(cfg)
+	closeFunctions["httpClient"] = httpClient.Close
+	endpointService, err := service.NewEndpointService(
+		httpClient,
+		newCheckTimeouts(cfg),
+		newBodyPolicy(cfg),
+		cfg.Location,
+		cfg.Check.CertificateWarningDays,
+		notificationService,
+		clusterService,
+		wg,
+		serviceMetrics,
+		checkLogRepo,
+		endpointRepo,
+		incidentRepo,
+		certificateRepo,
+		healthCheckAgentRepo,
+		teamRepo,
+		endpointGroupRepo,
+		locationStatusRepo,
+	)
 	if err != nil {
 		return nil, err
 	}
 	closeFunctions["endpointService"] = func() { endpointService.Shutdown() }
+	retentionPolicy := service.RetentionPolicy{
+		RawLogs:       cfg.Retention.RawLogs,
+		HourlyRollups: cfg.Retention.HourlyRollups,
+		DailyRollups:  cfg.Retention.DailyRollups,
+		Interval:      cfg.Retention.Interval,
+		BatchSize:     cfg.Retention.BatchSize,
+	}
+	retentionService, err := service.NewRetentionService(retentionPolicy, clusterService, wg, checkLogRepo, checkRollupRepo)
+	if err != nil {
+		return nil, err
+	}
+	closeFunctions["retentionService"] = retentionService.Shutdown
+	reportService := service.NewReportService(endpointRepo, checkLogRepo, checkRollupRepo)
+	incidentService := service.NewIncidentService(incidentRepo)
+	groupService := service.NewEndpointGroupService(endpointGroupRepo)
+	statusService := service.NewStatusService(endpointRepo, endpointGroupRepo, checkLogRepo, checkRollupRepo, incidentRepo)
+	probeService := service.NewProbeService(cfg.Location, newBodyPolicy(cfg), endpointRepo, checkLogRepo, locationStatusRepo)
+	manifestService := service.NewManifestService(endpointService, notificationService, endpointGroupRepo, teamRepo)
+	if err := applyManifest(cfg.Manifest, defaultScope, manifestService); err != nil {
+		return nil, err
+	}
+	authService, err := service.NewAuthService(
+		cfg.Auth.Disabled,
+		[]byte(cfg.Auth.JWTSecret),
+		cfg.Auth.TokenTTL,
+		cfg.Auth.AdminName,
+		cfg.Auth.AdminPassword,
+		teamService.DefaultTeamID(),
+		teamRepo,
+		userRepo,
+		apiKeyRepo,
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	// Controllers
 	endpointController := controllerV1.NewEndpointController(endpointService)
+	reportController := controllerV1.NewReportController(reportService)
+	incidentController := controllerV1.NewIncidentController(incidentService)
+	notificationController := controllerV1.NewNotificationController(notificationService)
+	agentController := controllerV1.NewAgentController(endpointService)
+	manifestController := controllerV1.NewManifestController(manifestService)
+	groupController := controllerV1.NewGroupController(groupService)
+	statusController := controllerV1.NewStatusController(statusService)
+	authController := controllerV1.NewAuthController(authService)
+	teamController := controllerV1.NewTeamController(teamService)
+	clusterController := controllerV1.NewClusterController(clusterService)
+	probeController := controllerV1.NewProbeController(probeService)
+
+	return api.NewControllerContainer(
+		endpointController,
+		reportController,
+		incidentController,
+		notificationController,
+		agentController,
+		manifestController,
+		groupController,
+		statusController,
+		authController,
+		teamController,
+		clusterController,
+		probeController,
+		serviceMetrics,
+	), nil
+}
 
-	return api.NewControllerContainer(endpointController), nil
+func newHTTPClient(cfg *config.Config) *httpclient.Client {
+	return httpclient.New(httpclient.PoolConfig{
+		MaxIdleConns:        cfg.HTTPClient.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HTTPClient.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.HTTPClient.IdleConnTimeout,
+		DNSResolver:         cfg.HTTPClient.DNSResolver,
+	})
+}
+
+func newCheckTimeouts(cfg *config.Config) service.CheckTimeouts {
+	return service.CheckTimeouts{
+		Connect:        cfg.Check.ConnectTimeout,
+		TLSHandshake:   cfg.Check.TLSHandshakeTimeout,
+		ResponseHeader: cfg.Check.ResponseHeaderTimeout,
+	}
+}
+
+func newBodyPolicy(cfg *config.Config) service.BodyPolicy {
+	return service.BodyPolicy{
+		MaxBytes:   cfg.Check.MaxBodyBytes,
+		FailedOnly: cfg.Check.FailedBodiesOnly,
+	}
 }
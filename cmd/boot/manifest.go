@@ -0,0 +1,33 @@
+package boot
+
+import (
+	"healthcheck/config"
+	"healthcheck/internal/repository"
+	"healthcheck/service"
+	"log"
+	"os"
+)
+
+// applyManifest reconciles the team of the scope with the manifest file configured for startup.
+func applyManifest(cfg config.ManifestConfig, scope repository.Scope, manifestService service.ManifestService) error {
+	if cfg.Path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return err
+	}
+	manifest, err := service.ParseManifest(data)
+	if err != nil {
+		return err
+	}
+
+	report, err := manifestService.ApplyManifest(scope, manifest, cfg.Prune)
+	if err != nil {
+		return err
+	}
+	log.Printf("manifest %s applied, created: %v, updated: %v, deleted: %v",
+		cfg.Path, report.Created, report.Updated, report.Deleted)
+	return nil
+}
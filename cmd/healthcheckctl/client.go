@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client calls the REST API, whose responses are wrapped in {"data", "error", "result"}.
+type client struct {
+	baseURL string
+	token   string // sent as a bearer token when set
+	http    *http.Client
+}
+
+func newClient(baseURL, token string) *client {
+	return &client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type apiResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Error  string          `json:"error"`
+	Result bool            `json:"result"`
+}
+
+// call sends the request and returns the data of a successful response.
+func (c *client) call(method, path string, query url.Values, body any) (json.RawMessage, error) {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	data, err := c.raw(method, path, query, reader, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	res := apiResponse{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("unexpected response: %w", err)
+	}
+	return res.Data, nil
+}
+
+// raw sends the request and returns the body of a successful response as is.
+func (c *client) raw(method, path string, query url.Values, body io.Reader, contentType string) ([]byte, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 {
+		failure := apiResponse{}
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			return nil, fmt.Errorf("%s (%d)", failure.Error, res.StatusCode)
+		}
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return data, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type endpoint struct {
+	ID          uint
+	Name        string
+	CheckType   string
+	URL         string
+	Interval    int
+	Retries     int
+	ActiveCheck bool
+	LastStatus  bool
+}
+
+func (e endpoint) status() string {
+	switch {
+	case !e.ActiveCheck:
+		return "inactive"
+	case e.LastStatus:
+		return "up"
+	default:
+		return "down"
+	}
+}
+
+func (c *cli) endpoints(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: endpoints needs a subcommand", errUsage)
+	}
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "list":
+		return c.listEndpoints()
+	case "create":
+		return c.createEndpoint(args)
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("%w: endpoints %s needs an id", errUsage, command)
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/v1/endpoints/%d", id)
+
+	switch command {
+	case "get":
+		return c.getEndpoint(path)
+	case "update":
+		return c.updateEndpoint(path, args[1:])
+	case "delete":
+		data, err := c.client.call(http.MethodDelete, path, nil, nil)
+		if err != nil {
+			return err
+		}
+		return c.printer.message(data)
+	case "activate", "deactivate":
+		data, err := c.client.call(http.MethodPatch, path, nil, map[string]any{"check": command})
+		if err != nil {
+			return err
+		}
+		return c.printer.message(data)
+	default:
+		return fmt.Errorf("%w: unknown endpoints subcommand %s", errUsage, command)
+	}
+}
+
+func (c *cli) listEndpoints() error {
+	data, err := c.client.call(http.MethodGet, "/api/v1/endpoints/", nil, nil)
+	if err != nil {
+		return err
+	}
+	if c.printer.json {
+		return c.printer.raw(data)
+	}
+
+	var endpoints []endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(endpoints))
+	for _, e := range endpoints {
+		rows = append(rows, []string{
+			strconv.Itoa(int(e.ID)),
+			orDash(e.Name),
+			orDash(e.CheckType),
+			e.URL,
+			fmt.Sprintf("%ds", e.Interval),
+			e.status(),
+		})
+	}
+	return c.printer.table([]string{"ID", "NAME", "TYPE", "URL", "INTERVAL", "STATUS"}, rows)
+}
+
+func (c *cli) getEndpoint(path string) error {
+	data, err := c.client.call(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	if c.printer.json {
+		return c.printer.raw(data)
+	}
+
+	e := endpoint{}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return c.printer.table([]string{"FIELD", "VALUE"}, [][]string{
+		{"id", strconv.Itoa(int(e.ID))},
+		{"name", orDash(e.Name)},
+		{"type", orDash(e.CheckType)},
+		{"url", e.URL},
+		{"interval", fmt.Sprintf("%ds", e.Interval)},
+		{"retries", strconv.Itoa(e.Retries)},
+		{"status", e.status()},
+	})
+}
+
+func (c *cli) createEndpoint(args []string) error {
+	body, err := parseEndpointFlags("endpoints create", args)
+	if err != nil {
+		return err
+	}
+	data, err := c.client.call(http.MethodPost, "/api/v1/endpoints/", nil, body)
+	if err != nil {
+		return err
+	}
+	return c.printer.message(data)
+}
+
+func (c *cli) updateEndpoint(path string, args []string) error {
+	body, err := parseEndpointFlags("endpoints update", args)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return fmt.Errorf("%w: nothing to update", errUsage)
+	}
+	data, err := c.client.call(http.MethodPatch, path, nil, body)
+	if err != nil {
+		return err
+	}
+	return c.printer.message(data)
+}
+
+// parseEndpointFlags builds a request body from an optional yaml or json file, overridden by
+// the flags that were set.
+func parseEndpointFlags(name string, args []string) (map[string]any, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("f", "", "yaml or json file with the endpoint fields of the API")
+	flags.String("name", "", "endpoint name")
+	flags.String("url", "", "url or host:port to check")
+	flags.String("check-type", "", "http, tcp, dns, tls, grpc or script")
+	flags.String("method", "", "http method")
+	flags.Int("interval", 0, "seconds between checks")
+	flags.Int("timeout", 0, "check timeout in seconds")
+	flags.Int("retries", 0, "retries before the endpoint is down")
+	flags.Uint("group", 0, "group id, 0 removes the endpoint from its group")
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("%w: unexpected argument %s", errUsage, flags.Arg(0))
+	}
+
+	body := map[string]any{}
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &body); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", *file, err)
+		}
+	}
+
+	keys := map[string]string{
+		"check-type": "check_type",
+		"method":     "http_method",
+		"group":      "group_id",
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "f" {
+			return
+		}
+		key, ok := keys[f.Name]
+		if !ok {
+			key = f.Name
+		}
+		body[key] = f.Value.(flag.Getter).Get()
+	})
+	return body, nil
+}
+
+func parseID(s string) (uint, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %s", s)
+	}
+	return uint(id), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
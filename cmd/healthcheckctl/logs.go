@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type checkLog struct {
+	ID               uint
+	CreatedAt        time.Time
+	Location         string
+	Attempt          int
+	ResultStatusCode int
+	ErrorClass       string
+	Error            string
+	FailedAssertion  string
+	Duration         float64
+	raw              json.RawMessage
+}
+
+func (l checkLog) row() []string {
+	result := "ok"
+	if l.ErrorClass != "" {
+		result = l.ErrorClass
+	}
+	detail := l.Error
+	if l.FailedAssertion != "" {
+		detail = l.FailedAssertion
+	}
+	return []string{
+		formatTime(&l.CreatedAt),
+		orDash(l.Location),
+		strconv.Itoa(l.Attempt),
+		strconv.Itoa(l.ResultStatusCode),
+		result,
+		formatMillis(l.Duration),
+		orDash(truncate(detail, 80)),
+	}
+}
+
+var checkLogHeader = []string{"TIME", "LOCATION", "ATTEMPT", "STATUS", "RESULT", "DURATION", "DETAIL"}
+
+// logs prints the latest check logs of an endpoint oldest first, with -follow it keeps polling
+// for new ones.
+func (c *cli) logs(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: logs needs an endpoint id", errUsage)
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "number of logs to print")
+	follow := flags.Bool("follow", false, "keep printing new logs")
+	poll := flags.Duration("poll", 5*time.Second, "polling interval with -follow")
+	location := flags.String("location", "", "only print the logs of checks run from this location")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+
+	path := fmt.Sprintf("/api/v1/endpoints/%d/logs", id)
+	logs, err := c.fetchLogs(path, *location, *limit)
+	if err != nil {
+		return err
+	}
+
+	var lastID uint
+	if len(logs) > 0 {
+		lastID = logs[len(logs)-1].ID
+	}
+	if err := c.printLogs(logs, true); err != nil {
+		return err
+	}
+	if !*follow {
+		return nil
+	}
+
+	for range time.Tick(*poll) {
+		logs, err := c.fetchLogs(path, *location, 100)
+		if err != nil {
+			return err
+		}
+		var newLogs []checkLog
+		for _, l := range logs {
+			if l.ID > lastID {
+				newLogs = append(newLogs, l)
+			}
+		}
+		if len(newLogs) == 0 {
+			continue
+		}
+		lastID = newLogs[len(newLogs)-1].ID
+		if err := c.printLogs(newLogs, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchLogs returns the latest logs oldest first, of every location when location is empty.
+func (c *cli) fetchLogs(path, location string, limit int) ([]checkLog, error) {
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if location != "" {
+		query.Set("location", location)
+	}
+	data, err := c.client.call(http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	page := struct {
+		CheckLogs []json.RawMessage `json:"check_logs"`
+	}{}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+
+	logs := make([]checkLog, len(page.CheckLogs))
+	for i, raw := range page.CheckLogs {
+		l := checkLog{raw: raw}
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return nil, err
+		}
+		logs[len(logs)-1-i] = l
+	}
+	return logs, nil
+}
+
+// printLogs prints fixed width lines, or one json object per line, so that followed output
+// lines up and can be streamed to other tools.
+func (c *cli) printLogs(logs []checkLog, header bool) error {
+	if c.printer.json {
+		for _, l := range logs {
+			if _, err := fmt.Fprintln(c.printer.out, string(l.raw)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if header {
+		if err := printLogLine(c.printer.out, checkLogHeader); err != nil {
+			return err
+		}
+	}
+	for _, l := range logs {
+		if err := printLogLine(c.printer.out, l.row()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printLogLine(w io.Writer, columns []string) error {
+	_, err := fmt.Fprintf(w, "%-19s  %-12s  %-7s  %-6s  %-18s  %-8s  %s\n",
+		columns[0], columns[1], columns[2], columns[3], columns[4], columns[5], columns[6])
+	return err
+}
@@ -0,0 +1,99 @@
+// Command healthcheckctl manages a healthcheck server through its REST API.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `usage: healthcheckctl [-server url] [-token token] [-o table|json] <command> [arguments]
+
+commands:
+  endpoints list
+  endpoints get <id>
+  endpoints create [-f file] [endpoint flags]
+  endpoints update <id> [-f file] [endpoint flags]
+  endpoints delete <id>
+  endpoints activate <id>
+  endpoints deactivate <id>
+  logs <id> [-limit n] [-location name] [-follow] [-poll duration]
+  stats [id] [-window 24h|7d|30d]
+  incidents [-endpoint id] [-status open|resolved] [-limit n]
+  apply -f file [-prune]
+  export [-format yaml|json] [-out file]
+
+global flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	global := flag.NewFlagSet("healthcheckctl", flag.ExitOnError)
+	server := global.String("server", envOr("HEALTHCHECK_SERVER", "http://localhost:8000"), "server url, defaults to $HEALTHCHECK_SERVER")
+	token := global.String("token", os.Getenv("HEALTHCHECK_TOKEN"), "api key or token, defaults to $HEALTHCHECK_TOKEN")
+	output := global.String("o", "table", "output format, table or json")
+	global.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		global.PrintDefaults()
+	}
+	global.Parse(os.Args[1:])
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintln(os.Stderr, "output format must be table or json")
+		os.Exit(2)
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	c := &cli{
+		client:  newClient(*server, *token),
+		printer: &printer{out: os.Stdout, json: *output == "json"},
+	}
+	if err := c.run(global.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			if err != errUsage {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			global.Usage()
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+type cli struct {
+	client  *client
+	printer *printer
+}
+
+func (c *cli) run(args []string) error {
+	command, args := args[0], args[1:]
+	switch command {
+	case "endpoints", "endpoint":
+		return c.endpoints(args)
+	case "logs":
+		return c.logs(args)
+	case "stats":
+		return c.stats(args)
+	case "incidents":
+		return c.incidents(args)
+	case "apply":
+		return c.apply(args)
+	case "export":
+		return c.export(args)
+	default:
+		return fmt.Errorf("%w: unknown command %s", errUsage, command)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+func (c *cli) apply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	file := flags.String("f", "", "yaml or json manifest")
+	prune := flags.Bool("prune", false, "delete what the manifest does not declare")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: apply needs -f", errUsage)
+	}
+
+	manifest, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"prune": {strconv.FormatBool(*prune)}}
+	body, err := c.client.raw(http.MethodPost, "/api/v1/manifest", query, bytes.NewReader(manifest), "application/yaml")
+	if err != nil {
+		return err
+	}
+	res := apiResponse{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	if c.printer.json {
+		return c.printer.raw(res.Data)
+	}
+
+	report := struct {
+		Created []string `json:"created"`
+		Updated []string `json:"updated"`
+		Deleted []string `json:"deleted"`
+	}{}
+	if err := json.Unmarshal(res.Data, &report); err != nil {
+		return err
+	}
+	if len(report.Created)+len(report.Updated)+len(report.Deleted) == 0 {
+		_, err := fmt.Fprintln(c.printer.out, "no changes")
+		return err
+	}
+	for _, change := range []struct {
+		action string
+		names  []string
+	}{{"created", report.Created}, {"updated", report.Updated}, {"deleted", report.Deleted}} {
+		for _, name := range change.names {
+			fmt.Fprintf(c.printer.out, "%s %s\n", name, change.action)
+		}
+	}
+	return nil
+}
+
+// export writes the manifest in the format it is requested in, -o does not apply.
+func (c *cli) export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "yaml", "yaml or json")
+	out := flags.String("out", "", "file to write to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	manifest, err := c.client.raw(http.MethodGet, "/api/v1/manifest", url.Values{"format": {*format}}, nil, "")
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		return os.WriteFile(*out, manifest, 0o644)
+	}
+	_, err = c.printer.out.Write(manifest)
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printer writes API data either as indented json or as a table.
+type printer struct {
+	out  io.Writer
+	json bool
+}
+
+func (p *printer) raw(data json.RawMessage) error {
+	buf := bytes.Buffer{}
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := p.out.Write(buf.Bytes())
+	return err
+}
+
+// message prints the confirmation returned by create, update and delete calls.
+func (p *printer) message(data json.RawMessage) error {
+	if p.json {
+		return p.raw(data)
+	}
+	var message string
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(p.out, message)
+	return err
+}
+
+func (p *printer) table(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func formatMillis(ms float64) string {
+	return fmt.Sprintf("%.0fms", ms)
+}
+
+func formatSeconds(seconds float64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type endpointStats struct {
+	EndpointID   uint    `json:"endpoint_id"`
+	URL          string  `json:"url"`
+	TotalChecks  int     `json:"total_checks"`
+	FailedChecks int     `json:"failed_checks"`
+	Uptime       float64 `json:"uptime"`
+	Incidents    int     `json:"incidents"`
+	MTTR         float64 `json:"mttr"`
+	MTBF         float64 `json:"mtbf"`
+	LatencyP50   float64 `json:"latency_p50"`
+	LatencyP95   float64 `json:"latency_p95"`
+	LatencyP99   float64 `json:"latency_p99"`
+}
+
+func (s endpointStats) row() []string {
+	return []string{
+		strconv.Itoa(int(s.EndpointID)),
+		s.URL,
+		strconv.Itoa(s.TotalChecks),
+		strconv.Itoa(s.FailedChecks),
+		fmt.Sprintf("%.2f%%", s.Uptime),
+		strconv.Itoa(s.Incidents),
+		formatSeconds(s.MTTR),
+		formatMillis(s.LatencyP50),
+		formatMillis(s.LatencyP95),
+		formatMillis(s.LatencyP99),
+	}
+}
+
+var statsHeader = []string{"ID", "URL", "CHECKS", "FAILED", "UPTIME", "INCIDENTS", "MTTR", "P50", "P95", "P99"}
+
+// stats prints the stats of one endpoint, or of all endpoints when no id is given.
+func (c *cli) stats(args []string) error {
+	path := "/api/v1/stats"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		path = fmt.Sprintf("/api/v1/endpoints/%d/stats", id)
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	window := flags.String("window", "", "24h, 7d or 30d, defaults to 24h")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	query := url.Values{}
+	if *window != "" {
+		query.Set("window", *window)
+	}
+
+	data, err := c.client.call(http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	if c.printer.json {
+		return c.printer.raw(data)
+	}
+
+	if path != "/api/v1/stats" {
+		stats := endpointStats{}
+		if err := json.Unmarshal(data, &stats); err != nil {
+			return err
+		}
+		return c.printer.table(statsHeader, [][]string{stats.row()})
+	}
+
+	aggregate := struct {
+		endpointStats
+		Endpoints []endpointStats `json:"endpoints"`
+	}{}
+	if err := json.Unmarshal(data, &aggregate); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(aggregate.Endpoints)+1)
+	for _, stats := range aggregate.Endpoints {
+		rows = append(rows, stats.row())
+	}
+	rows = append(rows, []string{
+		"", "total",
+		strconv.Itoa(aggregate.TotalChecks),
+		strconv.Itoa(aggregate.FailedChecks),
+		fmt.Sprintf("%.2f%%", aggregate.Uptime),
+		strconv.Itoa(aggregate.Incidents),
+		"", "", "", "",
+	})
+	return c.printer.table(statsHeader, rows)
+}
+
+type incident struct {
+	ID            uint
+	EndpointID    uint
+	StartedAt     time.Time
+	EndedAt       *time.Time
+	Duration      int
+	FailureReason string
+	Acknowledged  bool
+}
+
+func (c *cli) incidents(args []string) error {
+	flags := flag.NewFlagSet("incidents", flag.ContinueOnError)
+	endpointID := flags.Uint("endpoint", 0, "only the incidents of this endpoint")
+	status := flags.String("status", "", "open or resolved")
+	limit := flags.Int("limit", 20, "number of incidents to print")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	if *endpointID > 0 {
+		query.Set("endpoint_id", strconv.Itoa(int(*endpointID)))
+	}
+	if *status != "" {
+		query.Set("status", *status)
+	}
+
+	data, err := c.client.call(http.MethodGet, "/api/v1/incidents/", query, nil)
+	if err != nil {
+		return err
+	}
+	if c.printer.json {
+		return c.printer.raw(data)
+	}
+
+	page := struct {
+		Incidents []incident `json:"incidents"`
+	}{}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(page.Incidents))
+	for _, i := range page.Incidents {
+		state := "open"
+		duration := time.Since(i.StartedAt).Round(time.Second).String()
+		if i.EndedAt != nil {
+			state = "resolved"
+			duration = formatSeconds(float64(i.Duration))
+		}
+		acknowledged := "no"
+		if i.Acknowledged {
+			acknowledged = "yes"
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(int(i.ID)),
+			strconv.Itoa(int(i.EndpointID)),
+			formatTime(&i.StartedAt),
+			state,
+			duration,
+			acknowledged,
+			orDash(truncate(i.FailureReason, 60)),
+		})
+	}
+	return c.printer.table([]string{"ID", "ENDPOINT", "STARTED", "STATE", "DURATION", "ACK", "REASON"}, rows)
+}
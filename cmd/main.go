@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"healthcheck/cmd/boot"
 	"healthcheck/config"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -26,7 +29,11 @@ func main() {
 	}
 
 	// boot
-	closeFunctions, wg, err := boot.Up(conf)
+	up := boot.Up
+	if conf.Mode == config.ModeProbe {
+		up = boot.Probe
+	}
+	closeFunctions, wg, err := up(conf)
 	if err != nil {
 		log.Println("could not boot", "err", err.Error())
 	}
@@ -37,6 +44,19 @@ func main() {
 }
 
 func setEnvConf(cfg *config.Config) error {
+	cfg.Mode = os.Getenv("MODE")
+	if cfg.Mode == "" {
+		cfg.Mode = config.ModeServer
+	}
+	if cfg.Mode != config.ModeServer && cfg.Mode != config.ModeProbe {
+		return fmt.Errorf("invalid MODE: %s", cfg.Mode)
+	}
+	cfg.Location = os.Getenv("LOCATION")
+	if cfg.Location == "" {
+		cfg.Location = "central"
+	}
+	cfg.Probe.ServerURL = os.Getenv("PROBE_SERVER_URL")
+	cfg.Probe.Token = os.Getenv("PROBE_TOKEN")
 	cfg.DB.Host = os.Getenv("POSTGRES_HOST")
 	cfg.DB.Port = os.Getenv("POSTGRES_PORT")
 	cfg.DB.User = os.Getenv("POSTGRES_USER")
@@ -44,6 +64,133 @@ func setEnvConf(cfg *config.Config) error {
 	cfg.DB.DBName = os.Getenv("POSTGRES_DB")
 
 	cfg.WebhookURL = os.Getenv("WEBHOOK_URL")
+	cfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	cfg.HTTPClient.DNSResolver = os.Getenv("DNS_RESOLVER")
+	cfg.Manifest.Path = os.Getenv("MANIFEST_PATH")
+	cfg.Auth.JWTSecret = os.Getenv("JWT_SECRET")
+	cfg.Auth.AdminName = os.Getenv("ADMIN_NAME")
+	if cfg.Auth.AdminName == "" {
+		cfg.Auth.AdminName = "admin"
+	}
+	cfg.Auth.AdminPassword = os.Getenv("ADMIN_PASSWORD")
+	cfg.DefaultTeam = os.Getenv("DEFAULT_TEAM")
+	if cfg.DefaultTeam == "" {
+		cfg.DefaultTeam = "default"
+	}
+	cfg.Cluster.InstanceID = os.Getenv("INSTANCE_ID")
+
+	var err error
+	if cfg.Delivery.MaxAttempts, err = intEnv("DELIVERY_MAX_ATTEMPTS", 8); err != nil {
+		return err
+	}
+	if cfg.Delivery.BaseBackoff, err = durationEnv("DELIVERY_BASE_BACKOFF", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.Delivery.MaxBackoff, err = durationEnv("DELIVERY_MAX_BACKOFF", time.Hour); err != nil {
+		return err
+	}
+	if cfg.Scheduler.Workers, err = intEnv("MAX_CONCURRENT_CHECKS", 100); err != nil {
+		return err
+	}
+	if cfg.Check.ConnectTimeout, err = durationEnv("CHECK_CONNECT_TIMEOUT", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.Check.TLSHandshakeTimeout, err = durationEnv("CHECK_TLS_HANDSHAKE_TIMEOUT", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.Check.ResponseHeaderTimeout, err = durationEnv("CHECK_RESPONSE_HEADER_TIMEOUT", 0); err != nil {
+		return err
+	}
+	if cfg.Check.CertificateWarningDays, err = intEnv("CERT_EXPIRY_WARNING_DAYS", 14); err != nil {
+		return err
+	}
+	if cfg.HTTPClient.MaxIdleConns, err = intEnv("HTTP_MAX_IDLE_CONNS", 1000); err != nil {
+		return err
+	}
+	if cfg.HTTPClient.MaxIdleConnsPerHost, err = intEnv("HTTP_MAX_IDLE_CONNS_PER_HOST", 2); err != nil {
+		return err
+	}
+	if cfg.HTTPClient.IdleConnTimeout, err = durationEnv("HTTP_IDLE_CONN_TIMEOUT", 90*time.Second); err != nil {
+		return err
+	}
+	if cfg.Manifest.Prune, err = boolEnv("MANIFEST_PRUNE", false); err != nil {
+		return err
+	}
+	if cfg.Auth.Disabled, err = boolEnv("AUTH_DISABLED", false); err != nil {
+		return err
+	}
+	if cfg.Auth.TokenTTL, err = durationEnv("TOKEN_TTL", 12*time.Hour); err != nil {
+		return err
+	}
+	if cfg.Cluster.Enabled, err = boolEnv("CLUSTER_MODE", false); err != nil {
+		return err
+	}
+	if cfg.Cluster.HeartbeatInterval, err = durationEnv("CLUSTER_HEARTBEAT_INTERVAL", 5*time.Second); err != nil {
+		return err
+	}
+	if cfg.Cluster.InstanceTTL, err = durationEnv("CLUSTER_INSTANCE_TTL", 15*time.Second); err != nil {
+		return err
+	}
+	if cfg.Probe.SyncInterval, err = durationEnv("PROBE_SYNC_INTERVAL", 30*time.Second); err != nil {
+		return err
+	}
+	if cfg.Check.MaxBodyBytes, err = intEnv("CHECK_MAX_BODY_BYTES", 0); err != nil {
+		return err
+	}
+	if cfg.Check.FailedBodiesOnly, err = boolEnv("CHECK_FAILED_BODIES_ONLY", false); err != nil {
+		return err
+	}
+	if cfg.Retention.RawLogs, err = durationEnv("RETENTION_RAW_LOGS", 0); err != nil {
+		return err
+	}
+	if cfg.Retention.HourlyRollups, err = durationEnv("RETENTION_HOURLY_ROLLUPS", 90*24*time.Hour); err != nil {
+		return err
+	}
+	if cfg.Retention.DailyRollups, err = durationEnv("RETENTION_DAILY_ROLLUPS", 0); err != nil {
+		return err
+	}
+	if cfg.Retention.Interval, err = durationEnv("RETENTION_INTERVAL", time.Hour); err != nil {
+		return err
+	}
+	if cfg.Retention.BatchSize, err = intEnv("RETENTION_BATCH_SIZE", 1000); err != nil {
+		return err
+	}
 
 	return nil
 }
+
+func intEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return i, nil
+}
+
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func boolEnv(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
@@ -1,8 +1,28 @@
 package config
 
+import "time"
+
+const (
+	ModeServer = "server"
+	ModeProbe  = "probe"
+)
+
 type Config struct {
-	DB         DBConfig
-	WebhookURL string
+	Mode          string // server, or probe to only run the checks of Location for a server
+	Location      string // where the checks of this instance run from
+	Probe         ProbeConfig
+	DB            DBConfig
+	WebhookURL    string
+	WebhookSecret string
+	Delivery      DeliveryConfig
+	Scheduler     SchedulerConfig
+	Check         CheckConfig
+	HTTPClient    HTTPClientConfig
+	Manifest      ManifestConfig
+	Auth          AuthConfig
+	DefaultTeam   string // team owning what was created before teams, its admins manage teams
+	Cluster       ClusterConfig
+	Retention     RetentionConfig
 }
 
 type DBConfig struct {
@@ -12,3 +32,63 @@ type DBConfig struct {
 	Password string
 	DBName   string
 }
+
+type DeliveryConfig struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+type SchedulerConfig struct {
+	Workers int // maximum number of checks running concurrently
+}
+
+type CheckConfig struct {
+	ConnectTimeout         time.Duration
+	TLSHandshakeTimeout    time.Duration
+	ResponseHeaderTimeout  time.Duration
+	CertificateWarningDays int  // warn when a certificate expires within this many days
+	MaxBodyBytes           int  // response bodies stored with the check logs are truncated, 0 keeps them whole
+	FailedBodiesOnly       bool // only store the response bodies of failed checks
+}
+
+type HTTPClientConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DNSResolver         string
+}
+
+type ManifestConfig struct {
+	Path  string // manifest applied at startup, none when empty
+	Prune bool   // delete what the manifest does not declare
+}
+
+type AuthConfig struct {
+	Disabled      bool          // every request is made by an anonymous admin
+	JWTSecret     string        // signs tokens, a random one is used when empty
+	TokenTTL      time.Duration // lifetime of the tokens issued at login
+	AdminName     string        // admin user created at startup when missing
+	AdminPassword string        // generated and written once to stderr when empty
+}
+
+type ClusterConfig struct {
+	Enabled           bool          // share the endpoints with the other instances on the database
+	InstanceID        string        // generated from the hostname when empty
+	HeartbeatInterval time.Duration // how often instances announce themselves and rebalance
+	InstanceTTL       time.Duration // instances silent for longer are considered dead
+}
+
+type ProbeConfig struct {
+	ServerURL    string        // server the probe fetches its checks from and reports to
+	Token        string        // API key with the probe role
+	SyncInterval time.Duration // how often the probe fetches its checks
+}
+
+type RetentionConfig struct {
+	RawLogs       time.Duration // check logs older are pruned once rolled up, 0 keeps them
+	HourlyRollups time.Duration // 0 keeps them
+	DailyRollups  time.Duration // 0 keeps them
+	Interval      time.Duration // how often rollups are built and old rows pruned
+	BatchSize     int           // rows deleted per statement
+}
@@ -4,7 +4,36 @@ import "gorm.io/gorm"
 
 type CheckLog struct {
 	gorm.Model
+	TeamID           uint `gorm:"index"`
 	EndpointID       uint
+	Location         string `gorm:"index"` // where the check was run from
+	Attempt          int    // attempt number within the retry cycle, starting from 1
 	ResultStatusCode int
 	ResultBody       string
+	ErrorClass       CheckErrorClass
+	Error            string
+	FailedAssertion  string
+	Duration         float64 // in milliseconds
+	DNSDuration      float64 // in milliseconds
+	ConnectDuration  float64 // in milliseconds
+	TLSDuration      float64 // in milliseconds
+	TTFB             float64 // in milliseconds
+	StepResults      string  // json encoded []StepResult of scripted checks
+}
+
+type CheckErrorClass string
+
+const (
+	CheckErrorNone              CheckErrorClass = ""
+	CheckErrorTimeout           CheckErrorClass = "timeout"
+	CheckErrorDNS               CheckErrorClass = "dns_failure"
+	CheckErrorConnectionRefused CheckErrorClass = "connection_refused"
+	CheckErrorTLS               CheckErrorClass = "tls_error"
+	CheckErrorNon2xx            CheckErrorClass = "non_2xx"
+	CheckErrorAssertion         CheckErrorClass = "assertion_failed"
+	CheckErrorUnknown           CheckErrorClass = "unknown"
+)
+
+func (l *CheckLog) Succeeded() bool {
+	return l.ErrorClass == CheckErrorNone
 }
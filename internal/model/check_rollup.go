@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// CheckRollup aggregates the checks of an endpoint from one location over a bucket of time, it
+// outlives the check logs it was built from.
+type CheckRollup struct {
+	TeamID      uint      `gorm:"index" json:"team_id"`
+	EndpointID  uint      `gorm:"uniqueIndex:,composite:bucket" json:"endpoint_id"`
+	Location    string    `gorm:"uniqueIndex:,composite:bucket" json:"location"`
+	BucketStart time.Time `gorm:"uniqueIndex:,composite:bucket;index" json:"bucket_start"`
+	Checks      int       `json:"checks"`
+	Failures    int       `json:"failures"`
+	LatencyAvg  float64   `json:"latency_avg"` // in milliseconds
+	LatencyP50  float64   `json:"latency_p50"` // in milliseconds
+	LatencyP95  float64   `json:"latency_p95"` // in milliseconds
+	LatencyP99  float64   `json:"latency_p99"` // in milliseconds
+	LatencyMax  float64   `json:"latency_max"` // in milliseconds
+}
+
+type HourlyCheckRollup struct {
+	ID          uint `gorm:"primaryKey" json:"-"`
+	CheckRollup `gorm:"embedded"`
+}
+
+type DailyCheckRollup struct {
+	ID          uint `gorm:"primaryKey" json:"-"`
+	CheckRollup `gorm:"embedded"`
+}
+
+// RollupResolution is the length of the buckets of a rollup, buckets start on UTC boundaries.
+type RollupResolution string
+
+const (
+	RollupHourly RollupResolution = "hour"
+	RollupDaily  RollupResolution = "day"
+)
+
+var ErrInvalidResolution = errors.New("resolution must be hour or day")
+
+func (r RollupResolution) Validate() error {
+	if r != RollupHourly && r != RollupDaily {
+		return ErrInvalidResolution
+	}
+	return nil
+}
+
+// Bucket returns the start of the bucket containing t.
+func (r RollupResolution) Bucket(t time.Time) time.Time {
+	if r == RollupDaily {
+		return t.UTC().Truncate(24 * time.Hour)
+	}
+	return t.UTC().Truncate(time.Hour)
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"errors"
+	"healthcheck/pkg/checker"
+	"net"
+	"slices"
+	"strings"
+)
+
+type CheckType string
+
+const (
+	CheckHTTP   CheckType = "http"
+	CheckTCP    CheckType = "tcp"
+	CheckDNS    CheckType = "dns"
+	CheckTLS    CheckType = "tls"
+	CheckGRPC   CheckType = "grpc"
+	CheckScript CheckType = "script"
+)
+
+// Validate accepts an empty type, which is an http check.
+func (t CheckType) Validate() error {
+	if t != "" && t != CheckHTTP && t != CheckTCP && t != CheckDNS && t != CheckTLS && t != CheckGRPC &&
+		t != CheckScript {
+		return errors.New("invalid check type")
+	}
+	return nil
+}
+
+func (t CheckType) IsHTTP() bool {
+	return t == "" || t == CheckHTTP
+}
+
+// CheckConfig holds the options of the non http check types, the target of those checks is
+// the endpoint URL: a host:port address, a host name for dns checks, or the base url of the
+// steps of scripted checks.
+type CheckConfig struct {
+	RecordType  string       `json:"record_type,omitempty"` // dns, defaults to A
+	Expected    []string     `json:"expected,omitempty"`    // dns, values the answers must include
+	GRPCService string       `json:"grpc_service,omitempty"`
+	GRPCTLS     bool         `json:"grpc_tls,omitempty"` // grpc, connect with the endpoint TLS options
+	Steps       []ScriptStep `json:"steps,omitempty"`
+}
+
+// ValidateCheck checks the target and options of the endpoint against its check type.
+func (e *Endpoint) ValidateCheck() error {
+	if err := e.CheckType.Validate(); err != nil {
+		return err
+	}
+
+	switch e.CheckType {
+	case CheckTCP, CheckTLS, CheckGRPC:
+		if _, _, err := net.SplitHostPort(e.URL); err != nil {
+			return errors.New("target must be a host:port address")
+		}
+	case CheckDNS:
+		if e.URL == "" || strings.ContainsAny(e.URL, "/: ") {
+			return errors.New("target must be a host name")
+		}
+		if e.Check.RecordType != "" && !slices.Contains(checker.RecordTypes, strings.ToUpper(e.Check.RecordType)) {
+			return checker.ErrUnsupportedRecordType
+		}
+	case CheckScript:
+		return validateScript(e.Check.Steps)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	httpclient "healthcheck/pkg/http_client"
 	"net/http"
 
 	"gorm.io/gorm"
@@ -9,16 +10,157 @@ import (
 
 type Endpoint struct {
 	gorm.Model
+	TeamID             uint   `gorm:"index"`
+	Name               string `gorm:"index"` // unique within the team when set, identifies the endpoint in manifests
+	GroupID            *uint
+	Group              *EndpointGroup `json:",omitempty"`
+	CheckType          CheckType
 	URL                string
 	Interval           int // in seconds
+	Timeout            int // in seconds, at most Interval
 	HTTPMethod         HTTPMethod
 	HTTPRequestHeaders string
 	HTTPRequestBody    string
-	Retries            int // retries before submitting failure
+	Retries            int    // retries before submitting failure
+	SuccessCriteria    string // json encoded SuccessCriteria
+	CheckConfig        string // json encoded CheckConfig
+	FreshConnection    bool   // open a new connection for every check instead of pooling
+	Proxy              string // http, https or socks5 proxy url
+	DNSResolver        string // host:port of the DNS server, empty uses the default resolver
+	RedirectPolicy     RedirectPolicy
+	MaxRedirects       int    // 0 uses the client default
+	CABundle           string // PEM encoded certificates trusted instead of the system ones
+	ClientCert         string // PEM encoded certificate presented for mutual TLS
+	ClientKey          string `json:"-"`
+	InsecureSkipVerify bool
+	Public             bool   // shown on the status page by name, the url is never published
+	Locations          string // json encoded list of the locations checking the endpoint, empty for the server only
+	Quorum             int    // locations that must see the endpoint down for it to be down, 0 for a majority
 	LastStatus         bool
 	ActiveCheck        bool
 	CheckLogs          []CheckLog
+	Certificate        *TLSCertificate
 	Headers            map[string]string `gorm:"-:all"`
+	Criteria           SuccessCriteria   `gorm:"-:all"`
+	Check              CheckConfig       `gorm:"-:all"`
+	LocationNames      []string          `gorm:"-:all"`
+}
+
+// DefaultTimeout is used for endpoints without a timeout, capped by their interval.
+const DefaultTimeout = 10 // in seconds
+
+var (
+	ErrInvalidInterval = errors.New("interval must be positive")
+	ErrInvalidTimeout  = errors.New("timeout must be positive and not exceed the interval")
+	ErrPublicName      = errors.New("public endpoints need a name")
+	ErrInvalidLocation = errors.New("locations must be distinct and not empty")
+	ErrInvalidQuorum   = errors.New("quorum must be between 0 and the number of locations")
+)
+
+// ValidateSchedule checks the interval and timeout of the endpoint, a zero timeout is set
+// to the default one.
+func (e *Endpoint) ValidateSchedule() error {
+	if e.Interval <= 0 {
+		return ErrInvalidInterval
+	}
+	if e.Timeout == 0 {
+		e.Timeout = min(DefaultTimeout, e.Interval)
+	}
+	if e.Timeout < 0 || e.Timeout > e.Interval {
+		return ErrInvalidTimeout
+	}
+	return nil
+}
+
+// ValidateConnection checks the proxy, resolver and redirect settings of the endpoint.
+func (e *Endpoint) ValidateConnection() error {
+	if err := httpclient.ValidateProxy(e.Proxy); err != nil {
+		return err
+	}
+	if err := httpclient.ValidateResolver(e.DNSResolver); err != nil {
+		return err
+	}
+	if err := httpclient.ValidateTLS(e.TLSOptions()); err != nil {
+		return err
+	}
+	if err := e.RedirectPolicy.Validate(); err != nil {
+		return err
+	}
+	if e.MaxRedirects < 0 {
+		return errors.New("max redirects must not be negative")
+	}
+	return nil
+}
+
+// ValidateVisibility makes sure a public endpoint can be shown without its url.
+func (e *Endpoint) ValidateVisibility() error {
+	if e.Public && e.Name == "" {
+		return ErrPublicName
+	}
+	return nil
+}
+
+// ValidateLocations checks the locations and quorum of the endpoint, LocationNames must be set.
+func (e *Endpoint) ValidateLocations() error {
+	seen := make(map[string]bool, len(e.LocationNames))
+	for _, location := range e.LocationNames {
+		if location == "" || seen[location] {
+			return ErrInvalidLocation
+		}
+		seen[location] = true
+	}
+	if e.Quorum < 0 || e.Quorum > len(e.LocationNames) {
+		return ErrInvalidQuorum
+	}
+	return nil
+}
+
+// CheckedFrom returns the locations checking the endpoint, server is the location of the server
+// which checks the endpoints without locations.
+func (e *Endpoint) CheckedFrom(server string) []string {
+	if len(e.LocationNames) == 0 {
+		return []string{server}
+	}
+	return e.LocationNames
+}
+
+// Distributed reports whether the status of the endpoint is decided by a quorum of locations
+// rather than by the checks of the server alone.
+func (e *Endpoint) Distributed(server string) bool {
+	locations := e.CheckedFrom(server)
+	return len(locations) > 1 || locations[0] != server
+}
+
+// QuorumSize is the number of locations that must see the endpoint down for it to be down.
+func (e *Endpoint) QuorumSize(server string) int {
+	if e.Quorum > 0 {
+		return e.Quorum
+	}
+	return len(e.CheckedFrom(server))/2 + 1
+}
+
+func (e *Endpoint) TLSOptions() httpclient.TLSOptions {
+	return httpclient.TLSOptions{
+		CABundle:           e.CABundle,
+		ClientCert:         e.ClientCert,
+		ClientKey:          e.ClientKey,
+		InsecureSkipVerify: e.InsecureSkipVerify,
+	}
+}
+
+type RedirectPolicy string
+
+const (
+	RedirectFollow   RedirectPolicy = "follow"
+	RedirectNoFollow RedirectPolicy = "no_follow"
+)
+
+// Validate accepts an empty policy, which follows redirects.
+func (p RedirectPolicy) Validate() error {
+	if p != "" && p != RedirectFollow && p != RedirectNoFollow {
+		return errors.New("invalid redirect policy")
+	}
+	return nil
 }
 
 type HTTPMethod string
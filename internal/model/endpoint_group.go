@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// EndpointGroup gathers related endpoints, e.g. the ones of a service.
+type EndpointGroup struct {
+	gorm.Model
+	TeamID      uint   `gorm:"uniqueIndex:idx_team_group_name"`
+	Name        string `gorm:"uniqueIndex:idx_team_group_name"`
+	Description string
+}
@@ -3,15 +3,114 @@ package model
 import (
 	"context"
 	"sync"
+	"time"
 )
 
-type HealthCheckAgentFunctionSignature func(ctx context.Context, wg *sync.WaitGroup, endpoint *Endpoint)
+// HealthCheckAgentFunctionSignature runs a single check of an agent's endpoint, runs of the
+// same agent never overlap.
+type HealthCheckAgentFunctionSignature func(ctx context.Context, agent *HealthCheckAgent)
 
+// HealthCheckAgent is shared between the agent registry and the scheduled runs, its state
+// must only be accessed through its methods.
 type HealthCheckAgent struct {
 	ID        uint
-	IsActive  bool
-	Endpoint  *Endpoint
-	Context   context.Context
-	Cancel    context.CancelFunc
 	AgentFunc HealthCheckAgentFunctionSignature
+
+	// retry cycle of the agent, only accessed by its runs
+	Tries        int
+	FailedChecks []*CheckLog
+	Certificate  *TLSCertificate // latest certificate seen
+	Resolved     bool            // whether a run decided the status of the endpoint yet
+
+	mu                  sync.RWMutex
+	isActive            bool
+	endpoint            *Endpoint
+	lastStatus          bool
+	lastRunAt           *time.Time
+	consecutiveFailures int
+}
+
+type HealthCheckAgentState struct {
+	ID                  uint       `json:"id"`
+	URL                 string     `json:"url"`
+	Interval            int        `json:"interval"`
+	IsActive            bool       `json:"is_active"`
+	LastStatus          bool       `json:"last_status"`
+	LastRunAt           *time.Time `json:"last_run_at"`
+	NextRunAt           *time.Time `json:"next_run_at"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+func NewHealthCheckAgent(endpoint *Endpoint, fn HealthCheckAgentFunctionSignature) *HealthCheckAgent {
+	return &HealthCheckAgent{
+		ID:         endpoint.ID,
+		AgentFunc:  fn,
+		endpoint:   endpoint,
+		lastStatus: endpoint.LastStatus,
+	}
+}
+
+// Endpoint returns the current configuration of the agent, it must not be modified.
+func (a *HealthCheckAgent) Endpoint() *Endpoint {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.endpoint
+}
+
+func (a *HealthCheckAgent) IsActive() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.isActive
+}
+
+func (a *HealthCheckAgent) SetActive(active bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.isActive = active
+}
+
+func (a *HealthCheckAgent) LastStatus() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastStatus
+}
+
+// SetEndpoint replaces the configuration of the agent, it is picked up by the next run.
+func (a *HealthCheckAgent) SetEndpoint(endpoint *Endpoint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endpoint = endpoint
+}
+
+func (a *HealthCheckAgent) SetLastStatus(status bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastStatus = status
+}
+
+func (a *HealthCheckAgent) ConsecutiveFailures() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.consecutiveFailures
+}
+
+func (a *HealthCheckAgent) RecordRun(at time.Time, consecutiveFailures int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastRunAt = &at
+	a.consecutiveFailures = consecutiveFailures
+}
+
+func (a *HealthCheckAgent) State() HealthCheckAgentState {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return HealthCheckAgentState{
+		ID:                  a.ID,
+		URL:                 a.endpoint.URL,
+		Interval:            a.endpoint.Interval,
+		IsActive:            a.isActive,
+		LastStatus:          a.lastStatus,
+		LastRunAt:           a.lastRunAt,
+		ConsecutiveFailures: a.consecutiveFailures,
+	}
 }
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Incident struct {
+	gorm.Model
+	TeamID             uint `gorm:"index"`
+	EndpointID         uint
+	StartedAt          time.Time
+	EndedAt            *time.Time
+	Duration           int    // in seconds, set on recovery
+	TriggerCheckLogIDs string // json encoded list of the failed check log ids that opened the incident
+	FailureReason      string
+	Acknowledged       bool
+	AcknowledgedAt     *time.Time
+	AcknowledgedBy     string
+	Annotations        []IncidentAnnotation
+}
+
+type IncidentAnnotation struct {
+	gorm.Model
+	IncidentID uint
+	Author     string
+	Text       string
+}
+
+func (i *Incident) IsOpen() bool {
+	return i.EndedAt == nil
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Instance is a server taking part in cluster mode, it is live as long as its heartbeats are
+// recent. Heartbeats are stamped with the database clock so that instances agree on liveness
+// regardless of their own clocks.
+type Instance struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	Hostname    string    `json:"hostname"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `gorm:"index" json:"heartbeat_at"`
+}
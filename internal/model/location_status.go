@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// LocationStatus is the state of an endpoint as seen from one location. An endpoint checked
+// from several locations is down when a quorum of them see it down.
+type LocationStatus struct {
+	ID                  uint      `gorm:"primaryKey" json:"-"`
+	EndpointID          uint      `gorm:"uniqueIndex:idx_endpoint_location" json:"endpoint_id"`
+	Location            string    `gorm:"uniqueIndex:idx_endpoint_location" json:"location"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastCheckLogID      uint      `json:"last_check_log_id"`
+	CheckedAt           time.Time `json:"checked_at"`
+}
+
+// Down reports whether the location has seen the endpoint fail as many times in a row as it
+// retries.
+func (s *LocationStatus) Down(retries int) bool {
+	return s.ConsecutiveFailures >= max(retries, 1)
+}
+
+// Fresh reports whether the location checked the endpoint recently enough to count, a location
+// that stopped reporting, e.g. a probe that went away, counts neither as up nor as down.
+func (s *LocationStatus) Fresh(interval int, now time.Time) bool {
+	return now.Sub(s.CheckedAt) <= 3*time.Duration(interval)*time.Second
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type NotificationChannel struct {
+	gorm.Model
+	TeamID  uint   `gorm:"uniqueIndex:idx_team_channel_name"`
+	Name    string `gorm:"uniqueIndex:idx_team_channel_name"`
+	Type    ChannelType
+	Config  string        `json:"-"`                   // json encoded ChannelConfig
+	Options ChannelConfig `gorm:"-:all" json:"config"` // decoded Config, without its secrets when read by the API
+	Enabled bool          `gorm:"default:true"`
+}
+
+// NotificationSubscription routes the notifications of an endpoint to a channel.
+type NotificationSubscription struct {
+	gorm.Model
+	EndpointID uint `gorm:"uniqueIndex:idx_endpoint_channel"`
+	ChannelID  uint `gorm:"uniqueIndex:idx_endpoint_channel"`
+}
+
+type ChannelType string
+
+const (
+	ChannelWebhook   ChannelType = "webhook"
+	ChannelSlack     ChannelType = "slack"
+	ChannelEmail     ChannelType = "email"
+	ChannelPagerDuty ChannelType = "pagerduty"
+	ChannelTeams     ChannelType = "teams"
+)
+
+type ChannelConfig struct {
+	URL        string   `json:"url,omitempty"`         // webhook, slack, teams and optionally pagerduty
+	Secret     string   `json:"secret,omitempty"`      // webhook payload signing secret
+	RoutingKey string   `json:"routing_key,omitempty"` // pagerduty
+	SMTPHost   string   `json:"smtp_host,omitempty"`
+	SMTPPort   int      `json:"smtp_port,omitempty"`
+	Username   string   `json:"username,omitempty"`
+	Password   string   `json:"password,omitempty"`
+	From       string   `json:"from,omitempty"`
+	To         []string `json:"to,omitempty"`
+}
+
+// Redacted returns the config without the smtp password, the webhook secret and the pagerduty
+// routing key.
+func (c ChannelConfig) Redacted() ChannelConfig {
+	c.Secret = ""
+	c.RoutingKey = ""
+	c.Password = ""
+	return c
+}
+
+// WithSecrets returns the config with the secrets left blank taken from previous, so that an
+// update does not need to send them again.
+func (c ChannelConfig) WithSecrets(previous ChannelConfig) ChannelConfig {
+	if c.Secret == "" {
+		c.Secret = previous.Secret
+	}
+	if c.RoutingKey == "" {
+		c.RoutingKey = previous.RoutingKey
+	}
+	if c.Password == "" {
+		c.Password = previous.Password
+	}
+	return c
+}
+
+func (c ChannelConfig) Validate(channelType ChannelType) error {
+	switch channelType {
+	case ChannelWebhook, ChannelSlack, ChannelTeams:
+		if c.URL == "" {
+			return errors.New("url is required")
+		}
+	case ChannelPagerDuty:
+		if c.RoutingKey == "" {
+			return errors.New("routing key is required")
+		}
+	case ChannelEmail:
+		if c.SMTPHost == "" || c.SMTPPort == 0 || c.From == "" || len(c.To) == 0 {
+			return errors.New("smtp host, smtp port, from and to are required")
+		}
+	default:
+		return errors.New("invalid channel type")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationDelivery is a queued notification to a single channel.
+type NotificationDelivery struct {
+	gorm.Model
+	TeamID        uint `gorm:"index"`
+	ChannelID     uint // zero for the default webhook
+	EndpointID    uint
+	Payload       string         // json encoded notifier.Notification
+	Status        DeliveryStatus `gorm:"index"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	DeliveredAt   *time.Time
+	AttemptLogs   []DeliveryAttempt `gorm:"foreignKey:DeliveryID"`
+}
+
+type DeliveryAttempt struct {
+	gorm.Model
+	DeliveryID uint
+	Attempt    int
+	Error      string
+	Duration   float64 // in milliseconds
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+func (s DeliveryStatus) Validate() error {
+	if s != DeliveryPending && s != DeliveryDelivered && s != DeliveryDead {
+		return errors.New("invalid delivery status")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"healthcheck/pkg/jsonpath"
+	"regexp"
+)
+
+// ScriptStep is one request of a scripted check. Its url, headers and body may reference the
+// variables extracted by previous steps as {{name}}, a url starting with / is relative to the
+// endpoint url.
+type ScriptStep struct {
+	Name            string            `json:"name"`
+	Method          HTTPMethod        `json:"method"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            any               `json:"body,omitempty"` // sent as is when a string, json encoded otherwise
+	SuccessCriteria SuccessCriteria   `json:"success_criteria"`
+	Extract         []Extraction      `json:"extract,omitempty"`
+}
+
+type ExtractionSource string
+
+const (
+	ExtractJSONPath ExtractionSource = "json_path"
+	ExtractHeader   ExtractionSource = "header"
+	ExtractRegex    ExtractionSource = "regex"
+)
+
+// Extraction stores a value of a step response into a variable, Expression is a json path,
+// a header name or a regex whose first group, or whole match, is the value.
+type Extraction struct {
+	Variable   string           `json:"variable"`
+	From       ExtractionSource `json:"from"`
+	Expression string           `json:"expression"`
+}
+
+// StepResult is the outcome of one step, stored with the check log of the script.
+type StepResult struct {
+	Name       string  `json:"name"`
+	StatusCode int     `json:"status_code"`
+	Duration   float64 `json:"duration"` // in milliseconds
+	Error      string  `json:"error,omitempty"`
+}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateScript(steps []ScriptStep) error {
+	if len(steps) == 0 {
+		return errors.New("script must have at least one step")
+	}
+	for i, step := range steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (s *ScriptStep) validate() error {
+	if s.URL == "" {
+		return errors.New("url is required")
+	}
+	if s.Method != "" {
+		if err := s.Method.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := s.SuccessCriteria.Validate(); err != nil {
+		return err
+	}
+	for _, extraction := range s.Extract {
+		if !variableName.MatchString(extraction.Variable) {
+			return fmt.Errorf("invalid variable name %q", extraction.Variable)
+		}
+		switch extraction.From {
+		case ExtractJSONPath:
+			if err := jsonpath.Validate(extraction.Expression); err != nil {
+				return fmt.Errorf("%w: %s", err, extraction.Expression)
+			}
+		case ExtractHeader:
+			if extraction.Expression == "" {
+				return errors.New("header name is required")
+			}
+		case ExtractRegex:
+			if _, err := regexp.Compile(extraction.Expression); err != nil {
+				return fmt.Errorf("invalid extraction regex: %w", err)
+			}
+		default:
+			return fmt.Errorf("invalid extraction source %q", extraction.From)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"healthcheck/pkg/jsonpath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SuccessCriteria is the set of assertions a response must satisfy to be considered healthy.
+type SuccessCriteria struct {
+	StatusCodes  []string            `json:"status_codes,omitempty"` // e.g. "200", "2xx" or "200-299", defaults to 2xx
+	Headers      map[string]string   `json:"headers,omitempty"`      // an empty value only requires the header to be present
+	BodyContains string              `json:"body_contains,omitempty"`
+	BodyRegex    string              `json:"body_regex,omitempty"`
+	JSONPath     []JSONPathAssertion `json:"json_path,omitempty"`
+	MaxLatency   int                 `json:"max_latency,omitempty"` // in milliseconds
+}
+
+type JSONPathAssertion struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"` // a nil value only requires the path to exist
+}
+
+var defaultStatusCodes = []string{"2xx"}
+
+func (c *SuccessCriteria) Validate() error {
+	for _, pattern := range c.StatusCodes {
+		if _, _, err := parseStatusCodePattern(pattern); err != nil {
+			return err
+		}
+	}
+	if c.BodyRegex != "" {
+		if _, err := regexp.Compile(c.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body regex: %w", err)
+		}
+	}
+	for _, assertion := range c.JSONPath {
+		if err := jsonpath.Validate(assertion.Path); err != nil {
+			return fmt.Errorf("%w: %s", err, assertion.Path)
+		}
+	}
+	if c.MaxLatency < 0 {
+		return errors.New("invalid max latency")
+	}
+	return nil
+}
+
+func (c *SuccessCriteria) AcceptsStatusCode(code int) bool {
+	patterns := c.StatusCodes
+	if len(patterns) == 0 {
+		patterns = defaultStatusCodes
+	}
+	for _, pattern := range patterns {
+		min, max, err := parseStatusCodePattern(pattern)
+		if err == nil && code >= min && code <= max {
+			return true
+		}
+	}
+	return false
+}
+
+func parseStatusCodePattern(pattern string) (min, max int, err error) {
+	invalid := fmt.Errorf("invalid status code pattern: %q", pattern)
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+	if len(pattern) == 3 && strings.HasSuffix(pattern, "xx") {
+		class, err := strconv.Atoi(pattern[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, invalid
+		}
+		return class * 100, class*100 + 99, nil
+	}
+
+	if from, to, ok := strings.Cut(pattern, "-"); ok {
+		min, err1 := strconv.Atoi(from)
+		max, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || min < 100 || max > 599 || min > max {
+			return 0, 0, invalid
+		}
+		return min, max, nil
+	}
+
+	code, err := strconv.Atoi(pattern)
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, invalid
+	}
+	return code, code, nil
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Team owns endpoints, channels, users and API keys, which are only visible to its members.
+type Team struct {
+	gorm.Model
+	Name         string `gorm:"uniqueIndex"`
+	MaxEndpoints int    // 0 for no limit
+	MinInterval  int    // in seconds, shortest interval of its endpoints, 0 for no limit
+}
+
+var (
+	ErrEndpointQuota        = errors.New("team has reached its endpoint quota")
+	ErrIntervalBelowMinimum = errors.New("interval is below the minimum interval of the team")
+	ErrInvalidQuota         = errors.New("quotas must not be negative")
+)
+
+func (t *Team) ValidateQuotas() error {
+	if t.MaxEndpoints < 0 || t.MinInterval < 0 {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+// AllowsEndpoints reports whether the team may own count endpoints.
+func (t *Team) AllowsEndpoints(count int) bool {
+	return t.MaxEndpoints == 0 || count <= t.MaxEndpoints
+}
+
+// ValidateInterval checks the interval of an endpoint of the team against its minimum.
+func (t *Team) ValidateInterval(interval int) error {
+	if interval < t.MinInterval {
+		return ErrIntervalBelowMinimum
+	}
+	return nil
+}
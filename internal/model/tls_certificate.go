@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TLSCertificate is the certificate chain presented by an endpoint on its latest https check.
+type TLSCertificate struct {
+	gorm.Model
+	EndpointID      uint `gorm:"uniqueIndex"`
+	Subject         string
+	Issuer          string
+	SANs            string // json encoded list of subject alternative names
+	NotBefore       time.Time
+	NotAfter        time.Time
+	Fingerprint     string // sha256 of the leaf certificate
+	Chain           string // json encoded list of the chain certificates, leaf first
+	VerifyError     string // why the chain does not verify, empty when it is valid
+	Warning         string // reason of the last warning sent for this certificate
+	CheckedAt       time.Time
+	DaysUntilExpiry int `gorm:"-:all"`
+}
+
+// ExpiresInDays returns the number of whole days left before the certificate expires, it is
+// negative once expired.
+func (c *TLSCertificate) ExpiresInDays(now time.Time) int {
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
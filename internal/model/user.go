@@ -0,0 +1,78 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type User struct {
+	gorm.Model
+	TeamID       uint   `gorm:"index"`
+	Name         string `gorm:"uniqueIndex"`
+	PasswordHash string `json:"-"`
+	Role         Role
+}
+
+// APIKey authenticates scripts and integrations, only a hash of the key is stored.
+type APIKey struct {
+	gorm.Model
+	TeamID     uint `gorm:"index"`
+	Name       string
+	KeyID      string `gorm:"uniqueIndex"` // the clear part of the key, used to look it up
+	KeyHash    string `json:"-"`
+	Role       Role
+	CreatedBy  string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// Usable reports whether the key is neither revoked nor expired.
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Role grants the permissions of the roles below it: viewers read, editors also change
+// endpoints, channels and incidents, admins also manage users and API keys. Probes stand
+// apart, they only fetch the checks of their location and report their results.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+	RoleProbe  Role = "probe"
+)
+
+var ErrInvalidRole = errors.New("role must be viewer, editor, admin or probe")
+
+func (r Role) Validate() error {
+	if r != RoleProbe && r.level() == 0 {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// Allows reports whether the role grants the permissions of required, admins are allowed
+// what probes are.
+func (r Role) Allows(required Role) bool {
+	if required == RoleProbe {
+		return r == RoleProbe || r == RoleAdmin
+	}
+	return r.level() > 0 && r.level() >= required.level()
+}
+
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(model *model.APIKey) error
+	FetchAll(scope Scope) ([]*model.APIKey, error)
+	FetchByID(scope Scope, id uint) (*model.APIKey, error)
+	FetchByKeyID(keyID string) (*model.APIKey, error)
+	Revoke(id uint, at time.Time) error
+	UpdateLastUsed(id uint, at time.Time) error
+}
+
+type apiKeyGormRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyGormRepository{db}
+}
+
+func (r *apiKeyGormRepository) Create(model *model.APIKey) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating api key => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *apiKeyGormRepository) FetchAll(scope Scope) ([]*model.APIKey, error) {
+	var model []*model.APIKey
+	if err := scope.apply(r.db, "api_keys").Order("id").Find(&model).Error; err != nil {
+		log.Printf("error fetching api keys => %v", err)
+		return nil, ErrFetch
+	}
+	return model, nil
+}
+
+func (r *apiKeyGormRepository) FetchByID(scope Scope, id uint) (*model.APIKey, error) {
+	var model model.APIKey
+	if err := scope.apply(r.db, "api_keys").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching api key => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *apiKeyGormRepository) FetchByKeyID(keyID string) (*model.APIKey, error) {
+	var model model.APIKey
+	if err := r.db.Where("key_id = ?", keyID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching api key => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *apiKeyGormRepository) Revoke(id uint, at time.Time) error {
+	if err := r.db.Model(&model.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error; err != nil {
+		log.Printf("error revoking api key => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *apiKeyGormRepository) UpdateLastUsed(id uint, at time.Time) error {
+	if err := r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+		log.Printf("error updating api key => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
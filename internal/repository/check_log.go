@@ -1,15 +1,68 @@
 package repository
 
 import (
+	"database/sql"
 	"healthcheck/internal/model"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+type CheckLogFilter struct {
+	From        *time.Time
+	To          *time.Time
+	StatusCodes []int
+	Location    string
+	Cursor      uint // fetch logs with an id lower than the cursor
+	Limit       int
+}
+
+// DailyCheckCount is the number of checks and failed checks of an endpoint on a UTC day.
+type DailyCheckCount struct {
+	EndpointID uint
+	Day        time.Time
+	Total      int
+	Failed     int
+}
+
+// CheckLogSummary aggregates the check logs of an endpoint over a window, the percentiles use
+// the nearest-rank method.
+type CheckLogSummary struct {
+	EndpointID uint
+	Total      int
+	Failed     int
+	FirstAt    time.Time
+	LastAt     time.Time
+	LatencyP50 float64
+	LatencyP95 float64
+	LatencyP99 float64
+}
+
+// FailureRun is a run of consecutive failed checks of an endpoint, from its first to its last
+// failed check. RepairedAt is when the next check succeeded, nil when the window ends first.
+type FailureRun struct {
+	EndpointID uint
+	StartedAt  time.Time
+	EndedAt    time.Time
+	RepairedAt *time.Time
+}
+
 type CheckLogRepository interface {
-	Create(endpointID uint, statusCode int, body string) error
-	FetchByEndpointID(endpointID uint) ([]*model.Endpoint, error)
+	Create(checkLog *model.CheckLog) error
+	FetchByEndpointID(scope Scope, endpointID uint, filter CheckLogFilter) ([]*model.CheckLog, error)
+	FetchByIDs(ids []uint) ([]*model.CheckLog, error)
+	// Summarize aggregates the check logs of the endpoints created in [from, to], endpoints
+	// without check logs are left out.
+	Summarize(scope Scope, endpointIDs []uint, from, to time.Time) ([]CheckLogSummary, error)
+	// FetchFailureRuns returns the runs of failed checks of the endpoints created in [from, to]
+	// that are at least as long as the retries of their endpoint, oldest first.
+	FetchFailureRuns(scope Scope, endpointIDs []uint, from, to time.Time) ([]FailureRun, error)
+	CountDaily(scope Scope, endpointIDs []uint, from time.Time) ([]DailyCheckCount, error)
+	// Oldest returns when the oldest check log was created, the zero time when there is none.
+	Oldest() (time.Time, error)
+	// DeleteBefore deletes at most limit check logs created before before.
+	DeleteBefore(before time.Time, limit int) (int64, error)
 }
 
 type checkLogRepository struct {
@@ -20,20 +73,142 @@ func NewCheckLogRepository(db *gorm.DB) CheckLogRepository {
 	return &checkLogRepository{db}
 }
 
-func (r *checkLogRepository) Create(endpointID uint, statusCode int, body string) error {
-	model := &model.CheckLog{EndpointID: endpointID, ResultStatusCode: statusCode, ResultBody: body}
-	if err := r.db.Create(model).Error; err != nil {
+func (r *checkLogRepository) Create(checkLog *model.CheckLog) error {
+	if err := r.db.Create(checkLog).Error; err != nil {
 		log.Printf("error creating check log => %v", err)
 		return ErrCreate
 	}
 	return nil
 }
 
-func (r *checkLogRepository) FetchByEndpointID(endpointID uint) ([]*model.Endpoint, error) {
-	var checkLogs []*model.Endpoint
-	if err := r.db.Where("endpoint_id = ?", endpointID).Find(&checkLogs).Error; err != nil {
+func (r *checkLogRepository) FetchByEndpointID(scope Scope, endpointID uint, filter CheckLogFilter) ([]*model.CheckLog, error) {
+	query := scope.apply(r.db, "check_logs").Where("endpoint_id = ?", endpointID)
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if len(filter.StatusCodes) > 0 {
+		query = query.Where("result_status_code IN ?", filter.StatusCodes)
+	}
+	if filter.Location != "" {
+		query = query.Where("location = ?", filter.Location)
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var checkLogs []*model.CheckLog
+	if err := query.Order("id DESC").Find(&checkLogs).Error; err != nil {
+		log.Printf("error fetching check logs => %v", err)
+		return nil, ErrFetch
+	}
+	return checkLogs, nil
+}
+
+// FetchByIDs returns the check logs with the given ids, oldest first.
+func (r *checkLogRepository) FetchByIDs(ids []uint) ([]*model.CheckLog, error) {
+	var checkLogs []*model.CheckLog
+	if len(ids) == 0 {
+		return checkLogs, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Order("id ASC").Find(&checkLogs).Error; err != nil {
 		log.Printf("error fetching check logs => %v", err)
 		return nil, ErrFetch
 	}
 	return checkLogs, nil
 }
+
+func (r *checkLogRepository) Summarize(scope Scope, endpointIDs []uint, from, to time.Time) ([]CheckLogSummary, error) {
+	var summaries []CheckLogSummary
+	if len(endpointIDs) == 0 {
+		return summaries, nil
+	}
+	err := scope.apply(r.db.Model(&model.CheckLog{}), "check_logs").
+		Select(`endpoint_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE error_class <> '') AS failed,
+			MIN(created_at) AS first_at, MAX(created_at) AS last_at,
+			percentile_disc(0.5) WITHIN GROUP (ORDER BY duration) AS latency_p50,
+			percentile_disc(0.95) WITHIN GROUP (ORDER BY duration) AS latency_p95,
+			percentile_disc(0.99) WITHIN GROUP (ORDER BY duration) AS latency_p99`).
+		Where("endpoint_id IN ? AND created_at >= ? AND created_at <= ?", endpointIDs, from, to).
+		Group("endpoint_id").
+		Scan(&summaries).Error
+	if err != nil {
+		log.Printf("error summarizing check logs => %v", err)
+		return nil, ErrFetch
+	}
+	return summaries, nil
+}
+
+// FetchFailureRuns numbers the runs of each endpoint as the difference between the position of
+// a check among all the checks and among the checks with the same outcome, which is constant
+// along a run.
+func (r *checkLogRepository) FetchFailureRuns(scope Scope, endpointIDs []uint, from, to time.Time) ([]FailureRun, error) {
+	var runs []FailureRun
+	if len(endpointIDs) == 0 {
+		return runs, nil
+	}
+	checks := scope.apply(r.db.Model(&model.CheckLog{}), "check_logs").
+		Select(`endpoint_id, id, created_at, error_class <> '' AS failed,
+			ROW_NUMBER() OVER (PARTITION BY endpoint_id ORDER BY created_at, id) -
+				ROW_NUMBER() OVER (PARTITION BY endpoint_id, error_class <> '' ORDER BY created_at, id) AS run,
+			LEAD(created_at) OVER (PARTITION BY endpoint_id ORDER BY created_at, id) AS next_at`).
+		Where("endpoint_id IN ? AND created_at >= ? AND created_at <= ?", endpointIDs, from, to)
+	err := r.db.Table("(?) AS checks", checks).
+		Select(`checks.endpoint_id, MIN(checks.created_at) AS started_at, MAX(checks.created_at) AS ended_at,
+			(ARRAY_AGG(checks.next_at ORDER BY checks.created_at DESC, checks.id DESC))[1] AS repaired_at`).
+		Joins("JOIN endpoints ON endpoints.id = checks.endpoint_id").
+		Where("checks.failed").
+		Group("checks.endpoint_id, checks.run, endpoints.retries").
+		Having("COUNT(*) >= GREATEST(endpoints.retries, 1)").
+		Order("checks.endpoint_id, started_at").
+		Scan(&runs).Error
+	if err != nil {
+		log.Printf("error fetching failure runs => %v", err)
+		return nil, ErrFetch
+	}
+	return runs, nil
+}
+
+// CountDaily counts the checks of the endpoints per UTC day since from.
+func (r *checkLogRepository) CountDaily(scope Scope, endpointIDs []uint, from time.Time) ([]DailyCheckCount, error) {
+	var counts []DailyCheckCount
+	if len(endpointIDs) == 0 {
+		return counts, nil
+	}
+	err := scope.apply(r.db.Model(&model.CheckLog{}), "check_logs").
+		Select("endpoint_id, date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, "+
+			"COUNT(*) AS total, COUNT(*) FILTER (WHERE error_class <> '') AS failed").
+		Where("endpoint_id IN ? AND created_at >= ?", endpointIDs, from).
+		Group("endpoint_id, day").
+		Scan(&counts).Error
+	if err != nil {
+		log.Printf("error counting check logs => %v", err)
+		return nil, ErrFetch
+	}
+	return counts, nil
+}
+
+func (r *checkLogRepository) Oldest() (time.Time, error) {
+	var oldest sql.NullTime
+	if err := r.db.Model(&model.CheckLog{}).Select("MIN(created_at)").Row().Scan(&oldest); err != nil {
+		log.Printf("error fetching oldest check log => %v", err)
+		return time.Time{}, ErrFetch
+	}
+	return oldest.Time, nil
+}
+
+// DeleteBefore deletes the check logs for good rather than marking them deleted.
+func (r *checkLogRepository) DeleteBefore(before time.Time, limit int) (int64, error) {
+	batch := r.db.Unscoped().Model(&model.CheckLog{}).Select("id").Where("created_at < ?", before).Order("id").Limit(limit)
+	result := r.db.Unscoped().Where("id IN (?)", batch).Delete(&model.CheckLog{})
+	if result.Error != nil {
+		log.Printf("error deleting check logs => %v", result.Error)
+		return 0, ErrDelete
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"healthcheck/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CheckRollupRepository interface {
+	// Rollup aggregates the check logs created in [from, to) into the buckets of resolution,
+	// from and to must be bucket boundaries. Buckets rolled up before are recomputed.
+	Rollup(resolution model.RollupResolution, from, to time.Time) error
+	// LatestBucket returns the start of the latest bucket rolled up, the zero time when none is.
+	LatestBucket(resolution model.RollupResolution) (time.Time, error)
+	// OldestBucket returns the start of the oldest bucket kept, the zero time when none is.
+	OldestBucket(resolution model.RollupResolution) (time.Time, error)
+	// Summarize aggregates the rollups of the endpoints with a bucket starting in [from, to),
+	// the percentiles are the means of the percentiles of the buckets weighted by their checks.
+	Summarize(scope Scope, resolution model.RollupResolution, endpointIDs []uint, from, to time.Time) ([]CheckLogSummary, error)
+	// FetchByEndpointID returns the rollups of an endpoint with a bucket starting in [from, to],
+	// oldest first.
+	FetchByEndpointID(scope Scope, resolution model.RollupResolution, endpointID uint, location string, from, to time.Time) ([]*model.CheckRollup, error)
+	// CountDaily counts the checks of the endpoints per UTC day since from, from every location.
+	CountDaily(scope Scope, endpointIDs []uint, from time.Time) ([]DailyCheckCount, error)
+	// DeleteBefore deletes at most limit rollups with a bucket starting before before.
+	DeleteBefore(resolution model.RollupResolution, before time.Time, limit int) (int64, error)
+}
+
+type checkRollupGormRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckRollupRepository(db *gorm.DB) CheckRollupRepository {
+	return &checkRollupGormRepository{db}
+}
+
+func rollupTable(resolution model.RollupResolution) string {
+	if resolution == model.RollupDaily {
+		return "daily_check_rollups"
+	}
+	return "hourly_check_rollups"
+}
+
+// Rollup computes the percentiles with percentile_disc, the nearest-rank method of the reports.
+func (r *checkRollupGormRepository) Rollup(resolution model.RollupResolution, from, to time.Time) error {
+	err := r.db.Exec(`INSERT INTO `+rollupTable(resolution)+` (team_id, endpoint_id, location, bucket_start,
+			checks, failures, latency_avg, latency_p50, latency_p95, latency_p99, latency_max)
+		SELECT team_id, endpoint_id, location,
+			date_trunc(?, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket,
+			COUNT(*), COUNT(*) FILTER (WHERE error_class <> ''), AVG(duration),
+			percentile_disc(0.5) WITHIN GROUP (ORDER BY duration),
+			percentile_disc(0.95) WITHIN GROUP (ORDER BY duration),
+			percentile_disc(0.99) WITHIN GROUP (ORDER BY duration),
+			MAX(duration)
+		FROM check_logs
+		WHERE created_at >= ? AND created_at < ? AND deleted_at IS NULL
+		GROUP BY team_id, endpoint_id, location, bucket
+		ON CONFLICT (endpoint_id, location, bucket_start) DO UPDATE SET
+			team_id = EXCLUDED.team_id, checks = EXCLUDED.checks, failures = EXCLUDED.failures,
+			latency_avg = EXCLUDED.latency_avg, latency_p50 = EXCLUDED.latency_p50,
+			latency_p95 = EXCLUDED.latency_p95, latency_p99 = EXCLUDED.latency_p99,
+			latency_max = EXCLUDED.latency_max`,
+		string(resolution), from, to).Error
+	if err != nil {
+		log.Printf("error rolling up check logs => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *checkRollupGormRepository) LatestBucket(resolution model.RollupResolution) (time.Time, error) {
+	var latest sql.NullTime
+	err := r.db.Table(rollupTable(resolution)).Select("MAX(bucket_start)").Row().Scan(&latest)
+	if err != nil {
+		log.Printf("error fetching latest rollup => %v", err)
+		return time.Time{}, ErrFetch
+	}
+	return latest.Time, nil
+}
+
+func (r *checkRollupGormRepository) OldestBucket(resolution model.RollupResolution) (time.Time, error) {
+	var oldest sql.NullTime
+	err := r.db.Table(rollupTable(resolution)).Select("MIN(bucket_start)").Row().Scan(&oldest)
+	if err != nil {
+		log.Printf("error fetching oldest rollup => %v", err)
+		return time.Time{}, ErrFetch
+	}
+	return oldest.Time, nil
+}
+
+func (r *checkRollupGormRepository) Summarize(scope Scope, resolution model.RollupResolution, endpointIDs []uint, from, to time.Time) ([]CheckLogSummary, error) {
+	var summaries []CheckLogSummary
+	if len(endpointIDs) == 0 || !from.Before(to) {
+		return summaries, nil
+	}
+	table := rollupTable(resolution)
+	err := scope.apply(r.db.Table(table), table).
+		Select(`endpoint_id, SUM(checks) AS total, SUM(failures) AS failed,
+			MIN(bucket_start) AS first_at, MAX(bucket_start) AS last_at,
+			COALESCE(SUM(latency_p50 * checks) / NULLIF(SUM(checks), 0), 0) AS latency_p50,
+			COALESCE(SUM(latency_p95 * checks) / NULLIF(SUM(checks), 0), 0) AS latency_p95,
+			COALESCE(SUM(latency_p99 * checks) / NULLIF(SUM(checks), 0), 0) AS latency_p99`).
+		Where("endpoint_id IN ? AND bucket_start >= ? AND bucket_start < ?", endpointIDs, from, to).
+		Group("endpoint_id").
+		Scan(&summaries).Error
+	if err != nil {
+		log.Printf("error summarizing rollups => %v", err)
+		return nil, ErrFetch
+	}
+	return summaries, nil
+}
+
+func (r *checkRollupGormRepository) FetchByEndpointID(scope Scope, resolution model.RollupResolution, endpointID uint, location string, from, to time.Time) ([]*model.CheckRollup, error) {
+	table := rollupTable(resolution)
+	query := scope.apply(r.db.Table(table), table).
+		Where("endpoint_id = ? AND bucket_start >= ? AND bucket_start <= ?", endpointID, from, to)
+	if location != "" {
+		query = query.Where("location = ?", location)
+	}
+
+	var rollups []*model.CheckRollup
+	if err := query.Order("bucket_start ASC, location ASC").Find(&rollups).Error; err != nil {
+		log.Printf("error fetching rollups => %v", err)
+		return nil, ErrFetch
+	}
+	return rollups, nil
+}
+
+func (r *checkRollupGormRepository) CountDaily(scope Scope, endpointIDs []uint, from time.Time) ([]DailyCheckCount, error) {
+	var counts []DailyCheckCount
+	if len(endpointIDs) == 0 {
+		return counts, nil
+	}
+	table := rollupTable(model.RollupDaily)
+	err := scope.apply(r.db.Table(table), table).
+		Select("endpoint_id, bucket_start AS day, SUM(checks) AS total, SUM(failures) AS failed").
+		Where("endpoint_id IN ? AND bucket_start >= ?", endpointIDs, from).
+		Group("endpoint_id, bucket_start").
+		Scan(&counts).Error
+	if err != nil {
+		log.Printf("error counting rollups => %v", err)
+		return nil, ErrFetch
+	}
+	return counts, nil
+}
+
+func (r *checkRollupGormRepository) DeleteBefore(resolution model.RollupResolution, before time.Time, limit int) (int64, error) {
+	var rollup any = &model.HourlyCheckRollup{}
+	if resolution == model.RollupDaily {
+		rollup = &model.DailyCheckRollup{}
+	}
+	batch := r.db.Model(rollup).Select("id").Where("bucket_start < ?", before).Order("id").Limit(limit)
+	result := r.db.Where("id IN (?)", batch).Delete(rollup)
+	if result.Error != nil {
+		log.Printf("error deleting rollups => %v", result.Error)
+		return 0, ErrDelete
+	}
+	return result.RowsAffected, nil
+}
@@ -9,15 +9,20 @@ import (
 )
 
 var (
-	ErrCreate = errors.New("error creating model")
-	ErrFetch  = errors.New("error fetching model")
-	ErrUpdate = errors.New("error updating model")
-	ErrDelete = errors.New("error deleting model")
+	ErrCreate   = errors.New("error creating model")
+	ErrFetch    = errors.New("error fetching model")
+	ErrUpdate   = errors.New("error updating model")
+	ErrDelete   = errors.New("error deleting model")
+	ErrNotFound = errors.New("model not found")
 )
 
 type EndpointRepository interface {
 	Create(model *model.Endpoint) error
-	FetchAll() ([]*model.Endpoint, error)
+	FetchAll(scope Scope) ([]*model.Endpoint, error)
+	FetchByID(scope Scope, id uint) (*model.Endpoint, error)
+	FetchByName(scope Scope, name string) (*model.Endpoint, error)
+	Count(scope Scope) (int, error)
+	Update(model *model.Endpoint) error
 	UpdateCheckActivation(id uint, isActive bool) error
 	UpdateLastStatus(id uint, status bool) error
 	Delete(id uint) error
@@ -39,15 +44,63 @@ func (r *endpointGormRepository) Create(model *model.Endpoint) error {
 	return nil
 }
 
-func (r *endpointGormRepository) FetchAll() ([]*model.Endpoint, error) {
+func (r *endpointGormRepository) FetchAll(scope Scope) ([]*model.Endpoint, error) {
 	var model []*model.Endpoint
-	if err := r.db.Find(&model).Error; err != nil {
+	if err := scope.apply(r.db, "endpoints").Preload("Certificate").Find(&model).Error; err != nil {
 		log.Printf("error fetching endpoints => %v", err)
 		return nil, ErrFetch
 	}
 	return model, nil
 }
 
+func (r *endpointGormRepository) FetchByID(scope Scope, id uint) (*model.Endpoint, error) {
+	var model model.Endpoint
+	if err := scope.apply(r.db, "endpoints").Preload("Certificate").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching endpoint => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *endpointGormRepository) FetchByName(scope Scope, name string) (*model.Endpoint, error) {
+	var model model.Endpoint
+	if err := scope.apply(r.db, "endpoints").Where("name = ?", name).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching endpoint => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *endpointGormRepository) Count(scope Scope) (int, error) {
+	var count int64
+	if err := scope.apply(r.db.Model(&model.Endpoint{}), "endpoints").Count(&count).Error; err != nil {
+		log.Printf("error counting endpoints => %v", err)
+		return 0, ErrFetch
+	}
+	return int(count), nil
+}
+
+// Update persists the configuration of an endpoint, leaving its check state untouched.
+func (r *endpointGormRepository) Update(model *model.Endpoint) error {
+	err := r.db.Model(model).
+		Select("name", "group_id", "check_type", "url", "interval", "timeout", "http_method",
+			"http_request_headers", "http_request_body", "retries", "success_criteria", "check_config",
+			"fresh_connection", "proxy", "dns_resolver", "redirect_policy", "max_redirects",
+			"ca_bundle", "client_cert", "client_key", "insecure_skip_verify", "public", "locations", "quorum").
+		Updates(model).Error
+	if err != nil {
+		log.Printf("error updating endpoint => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
 func (r *endpointGormRepository) UpdateCheckActivation(id uint, isActive bool) error {
 	if err := r.db.Model(&model.Endpoint{}).Where("id = ?", id).Update("active_check", isActive).Error; err != nil {
 		log.Printf("error updating endpoint activation status => %v", err)
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type EndpointGroupRepository interface {
+	Create(model *model.EndpointGroup) error
+	FetchAll(scope Scope) ([]*model.EndpointGroup, error)
+	FetchByID(scope Scope, id uint) (*model.EndpointGroup, error)
+	Update(model *model.EndpointGroup) error
+	Delete(id uint) error
+}
+
+type endpointGroupGormRepository struct {
+	db *gorm.DB
+}
+
+func NewEndpointGroupRepository(db *gorm.DB) EndpointGroupRepository {
+	return &endpointGroupGormRepository{db}
+}
+
+func (r *endpointGroupGormRepository) Create(model *model.EndpointGroup) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating endpoint group => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *endpointGroupGormRepository) FetchAll(scope Scope) ([]*model.EndpointGroup, error) {
+	var model []*model.EndpointGroup
+	if err := scope.apply(r.db, "endpoint_groups").Order("name").Find(&model).Error; err != nil {
+		log.Printf("error fetching endpoint groups => %v", err)
+		return nil, ErrFetch
+	}
+	return model, nil
+}
+
+func (r *endpointGroupGormRepository) FetchByID(scope Scope, id uint) (*model.EndpointGroup, error) {
+	var model model.EndpointGroup
+	if err := scope.apply(r.db, "endpoint_groups").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching endpoint group => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *endpointGroupGormRepository) Update(model *model.EndpointGroup) error {
+	if err := r.db.Model(model).Select("name", "description").Updates(model).Error; err != nil {
+		log.Printf("error updating endpoint group => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+// Delete removes the group, its endpoints are left ungrouped.
+func (r *endpointGroupGormRepository) Delete(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Endpoint{}).Where("group_id = ?", id).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.EndpointGroup{}, id).Error
+	})
+	if err != nil {
+		log.Printf("error deleting endpoint group => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
@@ -2,95 +2,132 @@ package repository
 
 import (
 	"context"
-	"errors"
 	model "healthcheck/internal/model"
+	"healthcheck/pkg/scheduler"
+	"sort"
 	"sync"
-)
-
-var (
-	ErrActiveAgent   = errors.New("agent is active")
-	ErrInActiveAgent = errors.New("agent is not active")
+	"time"
 )
 
 type HealthCheckAgentRepository interface {
-	Create(endpoint *model.Endpoint, fn model.HealthCheckAgentFunctionSignature) error
-	Delete(id uint) error
-	Start(id uint, wg *sync.WaitGroup) error
-	Stop(id uint) error
+	// Sync makes the agent of endpoint match it: the agent is created when missing, takes the
+	// configuration of endpoint otherwise, and is started or stopped following its ActiveCheck.
+	// It returns whether the agent was active before.
+	Sync(endpoint *model.Endpoint, fn model.HealthCheckAgentFunctionSignature) (wasActive bool)
+	// Delete stops and removes the agent of an endpoint, it returns false when there is none.
+	Delete(id uint) bool
 	StopAll()
+	List(scope Scope) []model.HealthCheckAgentState
+	Get(scope Scope, id uint) (model.HealthCheckAgentState, error)
 }
 
+// agentInMemoryRepository guards its agents map with mu, lifecycle operations hold the lock for
+// their whole duration so that concurrent sync and delete calls are serialized.
+// Active agents are run by the scheduler.
 type agentInMemoryRepository struct {
-	agents map[uint]*model.HealthCheckAgent
+	mu        sync.Mutex
+	agents    map[uint]*model.HealthCheckAgent
+	scheduler *scheduler.Scheduler
 }
 
-func NewAgentInMemoryRepository() HealthCheckAgentRepository {
+func NewAgentInMemoryRepository(scheduler *scheduler.Scheduler) HealthCheckAgentRepository {
 	return &agentInMemoryRepository{
-		agents: make(map[uint]*model.HealthCheckAgent),
+		agents:    make(map[uint]*model.HealthCheckAgent),
+		scheduler: scheduler,
 	}
 }
 
-func (r *agentInMemoryRepository) Create(endpoint *model.Endpoint, fn model.HealthCheckAgentFunctionSignature) error {
-	_, ok := r.agents[endpoint.ID]
+// Sync hands a new configuration to an existing agent, its next run picks it up while keeping
+// its failure counter and last status.
+func (r *agentInMemoryRepository) Sync(endpoint *model.Endpoint, fn model.HealthCheckAgentFunctionSignature) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agent, ok := r.agents[endpoint.ID]
 	if ok {
-		return ErrCreate
+		agent.SetEndpoint(endpoint)
+	} else {
+		agent = model.NewHealthCheckAgent(endpoint, fn)
+		r.agents[endpoint.ID] = agent
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	agent := &model.HealthCheckAgent{
-		ID:        endpoint.ID,
-		Context:   ctx,
-		Cancel:    cancel,
-		Endpoint:  endpoint,
-		AgentFunc: fn,
+
+	wasActive := agent.IsActive()
+	switch {
+	case endpoint.ActiveCheck && !wasActive:
+		agent.SetActive(true)
+		r.scheduler.Add(agent.ID, interval(endpoint), func(ctx context.Context) {
+			agent.AgentFunc(ctx, agent)
+		})
+	case endpoint.ActiveCheck:
+		r.scheduler.Reschedule(agent.ID, interval(endpoint))
+	case wasActive:
+		agent.SetActive(false)
+		r.scheduler.Remove(agent.ID)
 	}
-	r.agents[agent.ID] = agent
-	return nil
+	return wasActive
 }
 
-func (r *agentInMemoryRepository) Delete(id uint) error {
+func (r *agentInMemoryRepository) Delete(id uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	agent, ok := r.agents[id]
 	if !ok {
-		return ErrFetch
+		return false
 	}
-	if agent.IsActive {
-		return ErrActiveAgent
+	if agent.IsActive() {
+		agent.SetActive(false)
+		r.scheduler.Remove(agent.ID)
 	}
 	delete(r.agents, id)
-	return nil
+	return true
 }
 
-func (r *agentInMemoryRepository) Start(id uint, wg *sync.WaitGroup) error {
-	agent, ok := r.agents[id]
-	if !ok {
-		return ErrFetch
+func (r *agentInMemoryRepository) StopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, agent := range r.agents {
+		if agent.IsActive() {
+			agent.SetActive(false)
+			r.scheduler.Remove(agent.ID)
+		}
 	}
-	if agent.IsActive {
-		return ErrActiveAgent
+}
+
+func (r *agentInMemoryRepository) List(scope Scope) []model.HealthCheckAgentState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]model.HealthCheckAgentState, 0, len(r.agents))
+	for _, agent := range r.agents {
+		if scope.includes(agent.Endpoint().TeamID) {
+			states = append(states, r.state(agent))
+		}
 	}
-	agent.IsActive = true
-	wg.Add(1)
-	go agent.AgentFunc(agent.Context, wg, agent.Endpoint)
-	return nil
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
 }
 
-func (r *agentInMemoryRepository) Stop(id uint) error {
+func (r *agentInMemoryRepository) Get(scope Scope, id uint) (model.HealthCheckAgentState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	agent, ok := r.agents[id]
-	if !ok {
-		return ErrFetch
+	if !ok || !scope.includes(agent.Endpoint().TeamID) {
+		return model.HealthCheckAgentState{}, ErrNotFound
 	}
-	if !agent.IsActive {
-		return ErrInActiveAgent
-	}
-	agent.IsActive = false
-	agent.Cancel()
-	return nil
+	return r.state(agent), nil
 }
 
-func (r *agentInMemoryRepository) StopAll() {
-	for _, agent := range r.agents {
-		if agent.IsActive {
-			agent.IsActive = false
-			agent.Cancel()
-		}
+func (r *agentInMemoryRepository) state(agent *model.HealthCheckAgent) model.HealthCheckAgentState {
+	state := agent.State()
+	if next, ok := r.scheduler.Next(agent.ID); ok {
+		state.NextRunAt = &next
 	}
+	return state
+}
+
+func interval(endpoint *model.Endpoint) time.Duration {
+	return time.Duration(endpoint.Interval) * time.Second
 }
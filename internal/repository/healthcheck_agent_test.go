@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"healthcheck/internal/model"
+	"healthcheck/pkg/scheduler"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAgentRepositoryConcurrentLifecycle(t *testing.T) {
+	const endpoints = 6
+	s := scheduler.New(4)
+	ctx, cancel := context.WithCancel(context.Background())
+	schedulerWG := &sync.WaitGroup{}
+	s.Run(ctx, schedulerWG)
+	defer func() {
+		cancel()
+		schedulerWG.Wait()
+	}()
+	repo := NewAgentInMemoryRepository(s)
+
+	var inFlight [endpoints + 1]atomic.Int32
+	check := func(ctx context.Context, agent *model.HealthCheckAgent) {
+		if inFlight[agent.ID].Add(1) > 1 {
+			t.Errorf("agent %d ran concurrently with itself", agent.ID)
+		}
+		agent.Tries++
+		agent.RecordRun(time.Now(), agent.ConsecutiveFailures()+1)
+		inFlight[agent.ID].Add(-1)
+	}
+	endpoint := func(id uint, active bool) *model.Endpoint {
+		endpoint := &model.Endpoint{TeamID: id % 2, Interval: 1, ActiveCheck: active}
+		endpoint.ID = id
+		return endpoint
+	}
+
+	// runs only start about a second after the agents, so the goroutines keep going until then
+	deadline := time.Now().Add(1500 * time.Millisecond)
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; time.Now().Before(deadline); i++ {
+				id := uint((g+i)%endpoints) + 1
+				switch i % 6 {
+				case 0, 1:
+					repo.Sync(endpoint(id, true), check)
+				case 2:
+					repo.Sync(endpoint(id, false), check)
+				case 3:
+					repo.Delete(id)
+				case 4:
+					repo.List(Scope{TeamID: id % 2})
+				case 5:
+					repo.Get(AllTeams, id)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for id := uint(1); id <= endpoints; id++ {
+		repo.Sync(endpoint(id, true), check)
+	}
+	repo.StopAll()
+	for _, state := range repo.List(AllTeams) {
+		if state.IsActive || state.NextRunAt != nil {
+			t.Fatalf("agent %d still scheduled after StopAll", state.ID)
+		}
+	}
+
+	for id := uint(1); id <= endpoints; id++ {
+		if !repo.Delete(id) {
+			t.Fatalf("agent %d missing", id)
+		}
+	}
+	if _, err := repo.Get(AllTeams, 1); err != ErrNotFound {
+		t.Fatalf("deleted agent returned %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IncidentFilter struct {
+	EndpointID uint
+	Open       *bool
+	Cursor     uint // fetch incidents with an id lower than the cursor
+	Limit      int
+}
+
+type IncidentRepository interface {
+	Create(incident *model.Incident) error
+	FetchAll(scope Scope, filter IncidentFilter) ([]*model.Incident, error)
+	FetchByID(scope Scope, id uint) (*model.Incident, error)
+	FetchOpenByEndpointID(endpointID uint) (*model.Incident, error)
+	Close(id uint, endedAt time.Time, duration int) error
+	Acknowledge(id uint, by string, at time.Time) error
+	CreateAnnotation(annotation *model.IncidentAnnotation) error
+}
+
+type incidentGormRepository struct {
+	db *gorm.DB
+}
+
+func NewIncidentRepository(db *gorm.DB) IncidentRepository {
+	return &incidentGormRepository{db}
+}
+
+func (r *incidentGormRepository) Create(incident *model.Incident) error {
+	if err := r.db.Create(incident).Error; err != nil {
+		log.Printf("error creating incident => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *incidentGormRepository) FetchAll(scope Scope, filter IncidentFilter) ([]*model.Incident, error) {
+	query := scope.apply(r.db.Model(&model.Incident{}), "incidents")
+	if filter.EndpointID > 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			query = query.Where("ended_at IS NULL")
+		} else {
+			query = query.Where("ended_at IS NOT NULL")
+		}
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var incidents []*model.Incident
+	if err := query.Order("id DESC").Find(&incidents).Error; err != nil {
+		log.Printf("error fetching incidents => %v", err)
+		return nil, ErrFetch
+	}
+	return incidents, nil
+}
+
+func (r *incidentGormRepository) FetchByID(scope Scope, id uint) (*model.Incident, error) {
+	var incident model.Incident
+	if err := scope.apply(r.db, "incidents").Preload("Annotations").First(&incident, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching incident => %v", err)
+		return nil, ErrFetch
+	}
+	return &incident, nil
+}
+
+func (r *incidentGormRepository) FetchOpenByEndpointID(endpointID uint) (*model.Incident, error) {
+	var incident model.Incident
+	err := r.db.Where("endpoint_id = ? AND ended_at IS NULL", endpointID).Order("id DESC").First(&incident).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching open incident => %v", err)
+		return nil, ErrFetch
+	}
+	return &incident, nil
+}
+
+func (r *incidentGormRepository) Close(id uint, endedAt time.Time, duration int) error {
+	err := r.db.Model(&model.Incident{}).Where("id = ?", id).Updates(map[string]any{
+		"ended_at": endedAt,
+		"duration": duration,
+	}).Error
+	if err != nil {
+		log.Printf("error closing incident => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *incidentGormRepository) Acknowledge(id uint, by string, at time.Time) error {
+	err := r.db.Model(&model.Incident{}).Where("id = ?", id).Updates(map[string]any{
+		"acknowledged":    true,
+		"acknowledged_at": at,
+		"acknowledged_by": by,
+	}).Error
+	if err != nil {
+		log.Printf("error acknowledging incident => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *incidentGormRepository) CreateAnnotation(annotation *model.IncidentAnnotation) error {
+	if err := r.db.Create(annotation).Error; err != nil {
+		log.Printf("error creating incident annotation => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
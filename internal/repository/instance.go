@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"healthcheck/internal/model"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InstanceRepository interface {
+	// Heartbeat registers the instance, or records that it is still alive.
+	Heartbeat(instance *model.Instance) error
+	// FetchLive returns the instances with a heartbeat within ttl, ordered by id.
+	FetchLive(ttl time.Duration) ([]*model.Instance, error)
+	Delete(id string) error
+	// DeleteExpired removes the instances without a heartbeat within ttl.
+	DeleteExpired(ttl time.Duration) error
+	// Lock blocks until no other instance holds the lock of name, and returns the function
+	// releasing it, which can be called more than once.
+	Lock(name string) (unlock func(), err error)
+}
+
+type instanceGormRepository struct {
+	db *gorm.DB
+}
+
+func NewInstanceRepository(db *gorm.DB) InstanceRepository {
+	return &instanceGormRepository{db}
+}
+
+func (r *instanceGormRepository) Heartbeat(instance *model.Instance) error {
+	err := r.db.Model(&model.Instance{}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]any{"heartbeat_at": gorm.Expr("NOW()")}),
+	}).Create(map[string]any{
+		"id":           instance.ID,
+		"hostname":     instance.Hostname,
+		"started_at":   instance.StartedAt,
+		"heartbeat_at": gorm.Expr("NOW()"),
+	}).Error
+	if err != nil {
+		log.Printf("error recording instance heartbeat => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *instanceGormRepository) FetchLive(ttl time.Duration) ([]*model.Instance, error) {
+	var instances []*model.Instance
+	err := r.db.
+		Where("heartbeat_at > NOW() - make_interval(secs => ?)", ttl.Seconds()).
+		Order("id").
+		Find(&instances).Error
+	if err != nil {
+		log.Printf("error fetching live instances => %v", err)
+		return nil, ErrFetch
+	}
+	return instances, nil
+}
+
+func (r *instanceGormRepository) Delete(id string) error {
+	if err := r.db.Where("id = ?", id).Delete(&model.Instance{}).Error; err != nil {
+		log.Printf("error deleting instance => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
+
+func (r *instanceGormRepository) DeleteExpired(ttl time.Duration) error {
+	err := r.db.
+		Where("heartbeat_at <= NOW() - make_interval(secs => ?)", ttl.Seconds()).
+		Delete(&model.Instance{}).Error
+	if err != nil {
+		log.Printf("error deleting expired instances => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
+
+// Lock takes a transaction scoped advisory lock, the transaction holds on to one connection of
+// the pool until the lock is released.
+func (r *instanceGormRepository) Lock(name string) (func(), error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		log.Printf("error taking lock %s => %v", name, tx.Error)
+		return nil, ErrFetch
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", name).Error; err != nil {
+		tx.Rollback()
+		log.Printf("error taking lock %s => %v", name, err)
+		return nil, ErrFetch
+	}
+	var once sync.Once
+	return func() { once.Do(func() { tx.Commit() }) }, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LocationStatusRepository interface {
+	// Record counts a stored check log in the status of its endpoint from its location.
+	Record(checkLog *model.CheckLog) error
+	FetchByEndpointID(endpointID uint) ([]*model.LocationStatus, error)
+	DeleteByEndpointID(endpointID uint) error
+}
+
+type locationStatusGormRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationStatusRepository(db *gorm.DB) LocationStatusRepository {
+	return &locationStatusGormRepository{db}
+}
+
+// Record increments the consecutive failures of the location in place, so that results of the
+// same location recorded concurrently through several instances are all counted.
+func (r *locationStatusGormRepository) Record(checkLog *model.CheckLog) error {
+	status := &model.LocationStatus{
+		EndpointID:     checkLog.EndpointID,
+		Location:       checkLog.Location,
+		LastCheckLogID: checkLog.ID,
+		CheckedAt:      checkLog.CreatedAt,
+	}
+	failures := gorm.Expr("0")
+	if !checkLog.Succeeded() {
+		status.ConsecutiveFailures = 1
+		failures = gorm.Expr("location_statuses.consecutive_failures + 1")
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint_id"}, {Name: "location"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"consecutive_failures": failures,
+			"last_check_log_id":    status.LastCheckLogID,
+			"checked_at":           status.CheckedAt,
+		}),
+	}).Create(status).Error
+	if err != nil {
+		log.Printf("error recording location status => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *locationStatusGormRepository) FetchByEndpointID(endpointID uint) ([]*model.LocationStatus, error) {
+	var statuses []*model.LocationStatus
+	if err := r.db.Where("endpoint_id = ?", endpointID).Order("location").Find(&statuses).Error; err != nil {
+		log.Printf("error fetching location statuses => %v", err)
+		return nil, ErrFetch
+	}
+	return statuses, nil
+}
+
+func (r *locationStatusGormRepository) DeleteByEndpointID(endpointID uint) error {
+	if err := r.db.Where("endpoint_id = ?", endpointID).Delete(&model.LocationStatus{}).Error; err != nil {
+		log.Printf("error deleting location statuses => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
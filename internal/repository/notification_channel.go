@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type NotificationChannelRepository interface {
+	Create(model *model.NotificationChannel) error
+	FetchAll(scope Scope) ([]*model.NotificationChannel, error)
+	FetchByID(scope Scope, id uint) (*model.NotificationChannel, error)
+	FetchByEndpointID(scope Scope, endpointID uint) ([]*model.NotificationChannel, error)
+	Update(model *model.NotificationChannel) error
+	Delete(id uint) error
+	Subscribe(endpointID, channelID uint) error
+	Unsubscribe(endpointID, channelID uint) error
+}
+
+type notificationChannelGormRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepository(db *gorm.DB) NotificationChannelRepository {
+	return &notificationChannelGormRepository{db}
+}
+
+func (r *notificationChannelGormRepository) Create(model *model.NotificationChannel) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating notification channel => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *notificationChannelGormRepository) FetchAll(scope Scope) ([]*model.NotificationChannel, error) {
+	var model []*model.NotificationChannel
+	if err := scope.apply(r.db, "notification_channels").Find(&model).Error; err != nil {
+		log.Printf("error fetching notification channels => %v", err)
+		return nil, ErrFetch
+	}
+	return model, nil
+}
+
+func (r *notificationChannelGormRepository) FetchByID(scope Scope, id uint) (*model.NotificationChannel, error) {
+	var model model.NotificationChannel
+	if err := scope.apply(r.db, "notification_channels").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching notification channel => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *notificationChannelGormRepository) FetchByEndpointID(scope Scope, endpointID uint) ([]*model.NotificationChannel, error) {
+	var channels []*model.NotificationChannel
+	err := scope.apply(r.db, "notification_channels").
+		Joins("JOIN notification_subscriptions ON notification_subscriptions.channel_id = notification_channels.id AND notification_subscriptions.deleted_at IS NULL").
+		Where("notification_subscriptions.endpoint_id = ?", endpointID).
+		Find(&channels).Error
+	if err != nil {
+		log.Printf("error fetching endpoint notification channels => %v", err)
+		return nil, ErrFetch
+	}
+	return channels, nil
+}
+
+func (r *notificationChannelGormRepository) Update(model *model.NotificationChannel) error {
+	if err := r.db.Model(model).Select("name", "type", "config", "enabled").Updates(model).Error; err != nil {
+		log.Printf("error updating notification channel => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *notificationChannelGormRepository) Delete(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("channel_id = ?", id).Delete(&model.NotificationSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.NotificationChannel{}, id).Error
+	})
+	if err != nil {
+		log.Printf("error deleting notification channel => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
+
+func (r *notificationChannelGormRepository) Subscribe(endpointID, channelID uint) error {
+	subscription := &model.NotificationSubscription{EndpointID: endpointID, ChannelID: channelID}
+	if err := r.db.Where(subscription).FirstOrCreate(subscription).Error; err != nil {
+		log.Printf("error creating notification subscription => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *notificationChannelGormRepository) Unsubscribe(endpointID, channelID uint) error {
+	err := r.db.Unscoped().
+		Where("endpoint_id = ? AND channel_id = ?", endpointID, channelID).
+		Delete(&model.NotificationSubscription{}).Error
+	if err != nil {
+		log.Printf("error deleting notification subscription => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeliveryFilter struct {
+	Status     model.DeliveryStatus
+	EndpointID uint
+	Cursor     uint // fetch deliveries with an id lower than the cursor
+	Limit      int
+}
+
+type NotificationDeliveryRepository interface {
+	Create(model *model.NotificationDelivery) error
+	FetchAll(scope Scope, filter DeliveryFilter) ([]*model.NotificationDelivery, error)
+	FetchByID(scope Scope, id uint) (*model.NotificationDelivery, error)
+	ClaimDue(now, until time.Time, limit int) ([]*model.NotificationDelivery, error)
+	Update(model *model.NotificationDelivery) error
+	CreateAttempt(model *model.DeliveryAttempt) error
+}
+
+type notificationDeliveryGormRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationDeliveryRepository(db *gorm.DB) NotificationDeliveryRepository {
+	return &notificationDeliveryGormRepository{db}
+}
+
+func (r *notificationDeliveryGormRepository) Create(model *model.NotificationDelivery) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating notification delivery => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *notificationDeliveryGormRepository) FetchAll(scope Scope, filter DeliveryFilter) ([]*model.NotificationDelivery, error) {
+	query := scope.apply(r.db.Model(&model.NotificationDelivery{}), "notification_deliveries")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EndpointID > 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var deliveries []*model.NotificationDelivery
+	if err := query.Order("id DESC").Find(&deliveries).Error; err != nil {
+		log.Printf("error fetching notification deliveries => %v", err)
+		return nil, ErrFetch
+	}
+	return deliveries, nil
+}
+
+func (r *notificationDeliveryGormRepository) FetchByID(scope Scope, id uint) (*model.NotificationDelivery, error) {
+	var delivery model.NotificationDelivery
+	if err := scope.apply(r.db, "notification_deliveries").Preload("AttemptLogs").First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching notification delivery => %v", err)
+		return nil, ErrFetch
+	}
+	return &delivery, nil
+}
+
+// ClaimDue returns the pending deliveries whose next attempt is due, oldest first, and moves
+// their next attempt to until so that other instances skip them while they are delivered.
+// Deliveries claimed by another instance that is still within its transaction are skipped.
+func (r *notificationDeliveryGormRepository) ClaimDue(now, until time.Time, limit int) ([]*model.NotificationDelivery, error) {
+	var deliveries []*model.NotificationDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&model.NotificationDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", until).Error
+	})
+	if err != nil {
+		log.Printf("error claiming due notification deliveries => %v", err)
+		return nil, ErrFetch
+	}
+	return deliveries, nil
+}
+
+func (r *notificationDeliveryGormRepository) Update(model *model.NotificationDelivery) error {
+	err := r.db.Model(model).
+		Select("status", "attempts", "next_attempt_at", "last_error", "delivered_at").
+		Updates(model).Error
+	if err != nil {
+		log.Printf("error updating notification delivery => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *notificationDeliveryGormRepository) CreateAttempt(model *model.DeliveryAttempt) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating delivery attempt => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
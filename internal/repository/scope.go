@@ -0,0 +1,31 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Scope restricts the queries of a repository to the rows of a team. The zero Scope spans
+// every team, it is only meant for background work such as running checks and delivering
+// notifications.
+type Scope struct {
+	TeamID uint
+}
+
+// AllTeams spans every team.
+var AllTeams = Scope{}
+
+func TeamScope(teamID uint) Scope {
+	return Scope{TeamID: teamID}
+}
+
+// apply filters query on the team_id column of table.
+func (s Scope) apply(query *gorm.DB, table string) *gorm.DB {
+	if s.TeamID == 0 {
+		return query
+	}
+	return query.Where(table+".team_id = ?", s.TeamID)
+}
+
+// includes reports whether a row of the team is in the scope, for repositories not backed by
+// the database.
+func (s Scope) includes(teamID uint) bool {
+	return s.TeamID == 0 || s.TeamID == teamID
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type TeamRepository interface {
+	Create(model *model.Team) error
+	FetchAll() ([]*model.Team, error)
+	FetchByID(id uint) (*model.Team, error)
+	FetchByName(name string) (*model.Team, error)
+	Update(model *model.Team) error
+	Delete(id uint) error
+	Adopt(id uint) error
+}
+
+type teamGormRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamGormRepository{db}
+}
+
+func (r *teamGormRepository) Create(model *model.Team) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating team => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *teamGormRepository) FetchAll() ([]*model.Team, error) {
+	var model []*model.Team
+	if err := r.db.Order("name").Find(&model).Error; err != nil {
+		log.Printf("error fetching teams => %v", err)
+		return nil, ErrFetch
+	}
+	return model, nil
+}
+
+func (r *teamGormRepository) FetchByID(id uint) (*model.Team, error) {
+	var model model.Team
+	if err := r.db.First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching team => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *teamGormRepository) FetchByName(name string) (*model.Team, error) {
+	var model model.Team
+	if err := r.db.Where("name = ?", name).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching team => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *teamGormRepository) Update(model *model.Team) error {
+	if err := r.db.Model(model).Select("name", "max_endpoints", "min_interval").Updates(model).Error; err != nil {
+		log.Printf("error updating team => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *teamGormRepository) Delete(id uint) error {
+	if err := r.db.Delete(&model.Team{}, id).Error; err != nil {
+		log.Printf("error deleting team => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
+
+// Adopt assigns the rows that belong to no team, created before teams existed, to the team.
+func (r *teamGormRepository) Adopt(id uint) error {
+	owned := []any{
+		&model.Endpoint{},
+		&model.CheckLog{},
+		&model.EndpointGroup{},
+		&model.Incident{},
+		&model.NotificationChannel{},
+		&model.NotificationDelivery{},
+		&model.User{},
+		&model.APIKey{},
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range owned {
+			err := tx.Unscoped().Model(m).
+				Where("team_id IS NULL OR team_id = 0").
+				Update("team_id", id).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error assigning rows to team => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
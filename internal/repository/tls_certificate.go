@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TLSCertificateRepository interface {
+	Save(certificate *model.TLSCertificate) error
+	FetchByEndpointID(endpointID uint) (*model.TLSCertificate, error)
+}
+
+type tlsCertificateGormRepository struct {
+	db *gorm.DB
+}
+
+func NewTLSCertificateRepository(db *gorm.DB) TLSCertificateRepository {
+	return &tlsCertificateGormRepository{db}
+}
+
+// Save stores the certificate as the latest one of its endpoint.
+func (r *tlsCertificateGormRepository) Save(certificate *model.TLSCertificate) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "deleted_at", "subject", "issuer", "sans", "not_before", "not_after",
+			"fingerprint", "chain", "verify_error", "warning", "checked_at",
+		}),
+	}).Create(certificate).Error
+	if err != nil {
+		log.Printf("error saving tls certificate => %v", err)
+		return ErrUpdate
+	}
+	return nil
+}
+
+func (r *tlsCertificateGormRepository) FetchByEndpointID(endpointID uint) (*model.TLSCertificate, error) {
+	var certificate model.TLSCertificate
+	if err := r.db.Where("endpoint_id = ?", endpointID).First(&certificate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching tls certificate => %v", err)
+		return nil, ErrFetch
+	}
+	return &certificate, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	Create(model *model.User) error
+	FetchAll(scope Scope) ([]*model.User, error)
+	FetchByID(scope Scope, id uint) (*model.User, error)
+	FetchByName(name string) (*model.User, error)
+	Delete(id uint) error
+}
+
+type userGormRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userGormRepository{db}
+}
+
+func (r *userGormRepository) Create(model *model.User) error {
+	if err := r.db.Create(model).Error; err != nil {
+		log.Printf("error creating user => %v", err)
+		return ErrCreate
+	}
+	return nil
+}
+
+func (r *userGormRepository) FetchAll(scope Scope) ([]*model.User, error) {
+	var model []*model.User
+	if err := scope.apply(r.db, "users").Order("name").Find(&model).Error; err != nil {
+		log.Printf("error fetching users => %v", err)
+		return nil, ErrFetch
+	}
+	return model, nil
+}
+
+func (r *userGormRepository) FetchByID(scope Scope, id uint) (*model.User, error) {
+	var model model.User
+	if err := scope.apply(r.db, "users").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching user => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+func (r *userGormRepository) FetchByName(name string) (*model.User, error) {
+	var model model.User
+	if err := r.db.Where("name = ?", name).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		log.Printf("error fetching user => %v", err)
+		return nil, ErrFetch
+	}
+	return &model, nil
+}
+
+// Delete removes the user for good so that the name can be used again.
+func (r *userGormRepository) Delete(id uint) error {
+	if err := r.db.Unscoped().Delete(&model.User{}, id).Error; err != nil {
+		log.Printf("error deleting user => %v", err)
+		return ErrDelete
+	}
+	return nil
+}
@@ -2,30 +2,65 @@ package main
 
 import (
 	"encoding/json"
+	"healthcheck/pkg/notifier"
+	"io"
 	"log"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 )
 
 func main() {
 
+	// signatures are verified only when WEBHOOK_SECRET is set
+	secret := os.Getenv("WEBHOOK_SECRET")
+
 	http.HandleFunc("/webhook/{id}", func(w http.ResponseWriter, r *http.Request) {
-		payload := struct {
-			Status bool `json:"status"`
-		}{}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 
-		decoder := json.NewDecoder(r.Body)
-		err := decoder.Decode(&payload)
+		if secret != "" {
+			err := notifier.Verify(secret, r.Header.Get(notifier.SignatureHeader), r.Header.Get(notifier.TimestampHeader), body, 5*time.Minute)
+			if err != nil {
+				log.Println("webhook rejected, err:", err.Error())
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		payload := notifier.WebhookPayload{}
+		err = json.Unmarshal(body, &payload)
 		if err != nil {
 			log.Println(err)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		log.Println("webhook called, id: ", strings.TrimPrefix(r.URL.Path, "/webhook/"), "status:", payload.Status)
+		log.Println("webhook called, id: ", strings.TrimPrefix(r.URL.Path, "/webhook/"), "event:", payload.Event,
+			"status:", payload.Status, "incident:", payload.IncidentID, "reason:", payload.FailureReason)
 		w.WriteHeader(http.StatusOK)
 	})
 
+	// stand-ins for the slack, teams and pagerduty channels
+	for _, channel := range []string{"slack", "teams", "pagerduty"} {
+		http.HandleFunc("POST /"+channel, func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil || !json.Valid(body) {
+				log.Println(channel, "invalid payload")
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			log.Println(channel, "called, payload:", string(body))
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
 	log.Println("webhook listening on port 8082")
 	if err := http.ListenAndServe(":8082", nil); err != nil {
 		log.Fatalln(err)
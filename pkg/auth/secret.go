@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// APIKeyPrefix starts every API key, which reads hc_<id>_<secret>.
+const APIKeyPrefix = "hc_"
+
+// GenerateAPIKey returns a new API key and its id, the id is stored in clear to look the key
+// up while only a hash of the whole key is stored.
+func GenerateAPIKey() (key, id string, err error) {
+	idBytes := make([]byte, 6)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	id = hex.EncodeToString(idBytes)
+	return APIKeyPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(secret), id, nil
+}
+
+// APIKeyID returns the id of a key in the format of GenerateAPIKey.
+func APIKeyID(key string) (string, bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	return id, ok && id != ""
+}
+
+// HashAPIKey hashes a key for storage, keys are random so a fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// RandomSecret returns n random bytes, e.g. to sign tokens when no secret is configured.
+func RandomSecret(n int) ([]byte, error) {
+	secret := make([]byte, n)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -0,0 +1,20 @@
+package checker
+
+import (
+	"context"
+	httpclient "healthcheck/pkg/http_client"
+)
+
+// Result is the outcome of a check, Body describes what was observed, e.g. the resolved
+// records or the serving status.
+type Result struct {
+	Body    []byte
+	Timings httpclient.Timings
+	TLS     *httpclient.TLSInfo
+}
+
+// Checker probes a target, its deadline comes from ctx. Like httpclient.Do it always returns
+// a non-nil Result so that timings are available for failed checks too.
+type Checker interface {
+	Check(ctx context.Context, target string) (*Result, error)
+}
@@ -0,0 +1,133 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnsupportedRecordType = errors.New("unsupported dns record type")
+	ErrNoRecords             = errors.New("no dns records found")
+)
+
+// RecordTypes are the record types a DNSChecker can resolve.
+var RecordTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT"}
+
+// DNSChecker resolves a record of the target host name and succeeds when it has answers
+// which include all the expected values.
+type DNSChecker struct {
+	RecordType string
+	Expected   []string
+	Resolver   string // host:port of the DNS server, empty uses the system resolver
+}
+
+func NewDNSChecker(recordType string, expected []string, resolver string) *DNSChecker {
+	return &DNSChecker{recordType, expected, resolver}
+}
+
+func (c *DNSChecker) Check(ctx context.Context, target string) (*Result, error) {
+	result := &Result{}
+	start := time.Now()
+
+	answers, err := c.lookup(ctx, target)
+	result.Timings.DNS = time.Since(start)
+	result.Timings.Total = result.Timings.DNS
+	result.Body = []byte(strings.Join(answers, "\n"))
+	if err != nil {
+		return result, err
+	}
+	if len(answers) == 0 {
+		return result, ErrNoRecords
+	}
+
+	for _, expected := range c.Expected {
+		if !slices.Contains(answers, normalizeRecord(expected)) {
+			return result, fmt.Errorf("expected %s record %q not found", c.recordType(), expected)
+		}
+	}
+	return result, nil
+}
+
+func (c *DNSChecker) recordType() string {
+	if c.RecordType == "" {
+		return "A"
+	}
+	return strings.ToUpper(c.RecordType)
+}
+
+func (c *DNSChecker) resolver() *net.Resolver {
+	if c.Resolver == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, c.Resolver)
+		},
+	}
+}
+
+func (c *DNSChecker) lookup(ctx context.Context, host string) ([]string, error) {
+	resolver := c.resolver()
+	var answers []string
+
+	switch c.recordType() {
+	case "A", "AAAA":
+		network := "ip4"
+		if c.recordType() == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		records, err := resolver.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			answers = append(answers, record.Host)
+		}
+	case "NS":
+		records, err := resolver.LookupNS(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			answers = append(answers, record.Host)
+		}
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, records...)
+	default:
+		return nil, ErrUnsupportedRecordType
+	}
+
+	for i := range answers {
+		answers[i] = normalizeRecord(answers[i])
+	}
+	return answers, nil
+}
+
+// normalizeRecord makes host names comparable regardless of case and of the trailing dot.
+func normalizeRecord(record string) string {
+	return strings.TrimSuffix(strings.ToLower(record), ".")
+}
@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// serveDNS answers the A and TXT queries for service.test. over UDP and reports every other
+// name as missing.
+func serveDNS(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	name := dnsmessage.MustNewName("service.test.")
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			question := query.Questions[0]
+
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			header := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60}
+			switch {
+			case question.Name != name:
+				response.RCode = dnsmessage.RCodeNameError
+			case question.Type == dnsmessage.TypeA:
+				header.Type = dnsmessage.TypeA
+				response.Answers = []dnsmessage.Resource{
+					{Header: header, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}},
+					{Header: header, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}},
+				}
+			case question.Type == dnsmessage.TypeTXT:
+				header.Type = dnsmessage.TypeTXT
+				response.Answers = []dnsmessage.Resource{
+					{Header: header, Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}}},
+				}
+			}
+
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestDNSChecker(t *testing.T) {
+	resolver := serveDNS(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		recordType string
+		expected   []string
+		wantErr    bool
+	}{
+		{"a records", "service.test", "A", nil, false},
+		{"default record type", "service.test", "", []string{"10.0.0.2"}, false},
+		{"expected records", "service.test", "a", []string{"10.0.0.1", "10.0.0.2"}, false},
+		{"missing expected record", "service.test", "A", []string{"10.0.0.3"}, true},
+		{"txt record", "service.test", "TXT", []string{"v=spf1 -all"}, false},
+		{"no aaaa records", "service.test", "AAAA", nil, true},
+		{"unknown name", "missing.test", "A", nil, true},
+		{"unsupported record type", "service.test", "SRV", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			res, err := NewDNSChecker(tt.recordType, tt.expected, resolver).Check(ctx, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() err = %v, wantErr %v, body %q", err, tt.wantErr, res.Body)
+			}
+		})
+	}
+}
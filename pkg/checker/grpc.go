@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	httpclient "healthcheck/pkg/http_client"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCChecker calls the grpc.health.v1 Check method of the host:port target and succeeds
+// when the service is SERVING, an empty service checks the server as a whole.
+type GRPCChecker struct {
+	Service string
+	TLS     *httpclient.TLSOptions // nil uses a plaintext connection
+}
+
+func NewGRPCChecker(service string, tls *httpclient.TLSOptions) *GRPCChecker {
+	return &GRPCChecker{service, tls}
+}
+
+func (c *GRPCChecker) Check(ctx context.Context, target string) (*Result, error) {
+	result := &Result{}
+
+	creds := insecure.NewCredentials()
+	if c.TLS != nil {
+		config, err := c.TLS.Config()
+		if err != nil {
+			return result, err
+		}
+		creds = credentials.NewTLS(config)
+	}
+
+	start := time.Now()
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+	result.Timings.Total = time.Since(start)
+	result.Timings.TTFB = result.Timings.Total
+	if err != nil {
+		return result, err
+	}
+
+	result.Body = []byte(res.GetStatus().String())
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return result, fmt.Errorf("service is %s", res.GetStatus())
+	}
+	return result, nil
+}
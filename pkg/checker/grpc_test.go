@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCChecker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("draining", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	tests := []struct {
+		name     string
+		service  string
+		wantErr  bool
+		wantBody string
+	}{
+		{"whole server", "", false, "SERVING"},
+		{"serving service", "serving", false, "SERVING"},
+		{"not serving service", "draining", true, "NOT_SERVING"},
+		{"unknown service", "missing", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			res, err := NewGRPCChecker(tt.service, nil).Check(ctx, listener.Addr().String())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(res.Body) != tt.wantBody {
+				t.Fatalf("Check() body = %q, want %q", res.Body, tt.wantBody)
+			}
+		})
+	}
+}
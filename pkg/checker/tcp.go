@@ -0,0 +1,30 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// TCPChecker succeeds when a connection to the host:port target can be established.
+type TCPChecker struct{}
+
+func NewTCPChecker() *TCPChecker {
+	return &TCPChecker{}
+}
+
+func (c *TCPChecker) Check(ctx context.Context, target string) (*Result, error) {
+	result := &Result{}
+	start := time.Now()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", target)
+	result.Timings.Connect = time.Since(start)
+	result.Timings.Total = result.Timings.Connect
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	result.Body = []byte(conn.RemoteAddr().String())
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package checker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTCPChecker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	open := listener.Addr().String()
+
+	// a port that was just released has nothing listening on it
+	released, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := released.Addr().String()
+	released.Close()
+
+	tests := []struct {
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{"listening", open, false},
+		{"closed port", closedAddr, true},
+		{"missing port", "127.0.0.1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			res, err := NewTCPChecker().Check(ctx, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res == nil {
+				t.Fatal("Check() returned a nil result")
+			}
+			if !tt.wantErr && string(res.Body) != tt.target {
+				t.Fatalf("Check() body = %q, want %q", res.Body, tt.target)
+			}
+		})
+	}
+}
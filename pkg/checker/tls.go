@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	httpclient "healthcheck/pkg/http_client"
+	"net"
+	"time"
+)
+
+// TLSChecker succeeds when a TLS handshake with the host:port target completes, without
+// sending any application data.
+type TLSChecker struct {
+	Options httpclient.TLSOptions
+}
+
+func NewTLSChecker(opts httpclient.TLSOptions) *TLSChecker {
+	return &TLSChecker{opts}
+}
+
+func (c *TLSChecker) Check(ctx context.Context, target string) (*Result, error) {
+	result := &Result{}
+
+	config, err := c.Options.Config()
+	if err != nil {
+		return result, err
+	}
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		return result, err
+	}
+	config.ServerName = host
+
+	start := time.Now()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", target)
+	result.Timings.Connect = time.Since(start)
+	if err != nil {
+		result.Timings.Total = time.Since(start)
+		return result, err
+	}
+	defer conn.Close()
+
+	tlsStart := time.Now()
+	tlsConn := tls.Client(conn, config)
+	err = tlsConn.HandshakeContext(ctx)
+	result.Timings.TLS = time.Since(tlsStart)
+	result.Timings.Total = time.Since(start)
+	if err != nil {
+		result.TLS = httpclient.TLSInfoFromError(err)
+		return result, err
+	}
+
+	state := tlsConn.ConnectionState()
+	result.TLS = httpclient.NewTLSInfo(&state, c.Options, host)
+	result.Body = []byte(result.TLS.Version)
+	return result, nil
+}
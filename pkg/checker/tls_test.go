@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"context"
+	"encoding/pem"
+	httpclient "healthcheck/pkg/http_client"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTLSChecker(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "https://")
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	tests := []struct {
+		name        string
+		opts        httpclient.TLSOptions
+		wantErr     bool
+		wantVerify  bool // whether the chain is expected to verify
+		wantSubject bool // whether the certificates of the server are reported
+	}{
+		{"trusted ca bundle", httpclient.TLSOptions{CABundle: ca}, false, true, true},
+		{"untrusted certificate", httpclient.TLSOptions{}, true, false, true},
+		{"verification skipped", httpclient.TLSOptions{InsecureSkipVerify: true}, false, false, true},
+		{"invalid ca bundle", httpclient.TLSOptions{CABundle: "not a pem"}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			res, err := NewTLSChecker(tt.opts).Check(ctx, target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantSubject {
+				return
+			}
+			if res.TLS == nil || len(res.TLS.Certificates) == 0 {
+				t.Fatalf("Check() TLS = %+v, want the server certificates", res.TLS)
+			}
+			if verified := res.TLS.VerifyError == ""; verified != tt.wantVerify {
+				t.Fatalf("Check() VerifyError = %q, want verified %v", res.TLS.VerifyError, tt.wantVerify)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package cluster assigns keys to the members of a cluster with rendezvous hashing. Members
+// that agree on the membership agree on the owner of every key, and when a member joins or
+// leaves only the keys it gains or owned change owner.
+package cluster
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Owner returns the member owning key, false when there are no members.
+func Owner(key uint, members []string) (string, bool) {
+	var owner string
+	var best uint64
+	for i, member := range members {
+		w := weight(key, member)
+		if i == 0 || w > best || (w == best && member < owner) {
+			owner, best = member, w
+		}
+	}
+	return owner, len(members) > 0
+}
+
+func weight(key uint, member string) uint64 {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(key))
+	h := fnv.New64a()
+	h.Write(b[:])
+	h.Write([]byte(member))
+	return mix(h.Sum64())
+}
+
+// mix is the finalizer of splitmix64, fnv alone spreads consecutive keys poorly.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
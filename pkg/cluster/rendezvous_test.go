@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"slices"
+	"testing"
+)
+
+const keys = 3000
+
+func owners(t *testing.T, members []string) map[uint]string {
+	t.Helper()
+	owned := make(map[uint]string, keys)
+	for key := uint(1); key <= keys; key++ {
+		owner, ok := Owner(key, members)
+		if !ok {
+			t.Fatalf("Owner(%d, %v) found no owner", key, members)
+		}
+		owned[key] = owner
+	}
+	return owned
+}
+
+func TestOwnerWithoutMembers(t *testing.T) {
+	if owner, ok := Owner(1, nil); ok || owner != "" {
+		t.Fatalf("Owner() = %q, %v, want no owner", owner, ok)
+	}
+}
+
+func TestOwnerIgnoresMemberOrder(t *testing.T) {
+	members := []string{"a", "b", "c", "d"}
+	want := owners(t, members)
+
+	reversed := slices.Clone(members)
+	slices.Reverse(reversed)
+	got := owners(t, reversed)
+	for key, owner := range want {
+		if got[key] != owner {
+			t.Fatalf("key %d is owned by %s or %s depending on the member order", key, owner, got[key])
+		}
+	}
+}
+
+func TestOwnerStability(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+	}{
+		{"member joins", []string{"a", "b"}, []string{"a", "b", "c"}},
+		{"member leaves", []string{"a", "b", "c"}, []string{"a", "c"}},
+		{"first member leaves", []string{"a", "b", "c", "d"}, []string{"b", "c", "d"}},
+		{"members join", []string{"node-1"}, []string{"node-1", "node-2", "node-3"}},
+		{"member replaced", []string{"a", "b", "c"}, []string{"a", "b", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := owners(t, tt.before)
+			after := owners(t, tt.after)
+
+			moved := 0
+			for key, owner := range before {
+				if after[key] == owner {
+					continue
+				}
+				moved++
+				// a key only moves away from a member that left, or to a member that joined
+				if slices.Contains(tt.after, owner) && slices.Contains(tt.before, after[key]) {
+					t.Fatalf("key %d moved from %s to %s, both members before and after", key, owner, after[key])
+				}
+			}
+			if moved == 0 {
+				t.Fatal("no key changed owner")
+			}
+		})
+	}
+}
+
+func TestOwnerBalance(t *testing.T) {
+	members := []string{"a", "b", "c", "d"}
+	counts := make(map[string]int)
+	for _, owner := range owners(t, members) {
+		counts[owner]++
+	}
+
+	fair := keys / len(members)
+	for _, member := range members {
+		if counts[member] < fair*3/4 || counts[member] > fair*5/4 {
+			t.Fatalf("member %s owns %d keys, want about %d", member, counts[member], fair)
+		}
+	}
+}
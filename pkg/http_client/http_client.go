@@ -3,36 +3,111 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"fmt"
 	"io"
-	"net"
 	"net/http"
+	"net/http/httptrace"
 	"time"
 )
 
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Timings    Timings
+	Reused     bool     // the request was sent on a pooled connection
+	TLS        *TLSInfo // set for https requests that got to the handshake
+}
+
+// DefaultMaxRedirects is the number of redirects followed when Options.MaxRedirects is unset.
+const DefaultMaxRedirects = 10
+
+// Options bounds the phases of a request, Timeout covers the whole request including reading
+// the body, the other timeouts default to it when unset and never exceed it.
+type Options struct {
+	Timeout               time.Duration
+	ConnectTimeout        time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// FreshConnection opens a new connection for the request instead of using the pool.
+	FreshConnection bool
+	// Proxy is an http, https or socks5 proxy url.
+	Proxy string
+	// DNSResolver is the host:port of a DNS server used instead of the system resolver.
+	DNSResolver string
+	// NoRedirects returns redirect responses as is instead of following them.
+	NoRedirects  bool
+	MaxRedirects int
+	TLS          TLSOptions
+}
+
+func (o Options) phase(timeout time.Duration) time.Duration {
+	if timeout <= 0 || (o.Timeout > 0 && timeout > o.Timeout) {
+		return o.Timeout
+	}
+	return timeout
+}
+
+func (o Options) checkRedirect(req *http.Request, via []*http.Request) error {
+	if o.NoRedirects {
+		return http.ErrUseLastResponse
+	}
+	maxRedirects := o.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+var defaultClient = New(PoolConfig{})
+
+// Do sends the request with a client shared by the whole process.
 func Do(
 	ctx context.Context,
 	method, url string,
 	data []byte,
-	timeout time.Duration,
 	headers map[string]string,
-) (
-	body []byte,
-	httpStatusCode int,
-	err error,
-) {
-	transport := &http.Transport{
-		DisableKeepAlives: true,
-		Dial: (&net.Dialer{
-			Timeout:   timeout,
-			KeepAlive: -1,
-		}).Dial,
-		// TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
-		TLSHandshakeTimeout: timeout,
+	opts Options,
+) (*Response, error) {
+	return defaultClient.Do(ctx, method, url, data, headers, opts)
+}
+
+// Do always returns a non-nil Response so that timings are available for failed requests too,
+// StatusCode is -1 when no response was received.
+func (c *Client) Do(
+	ctx context.Context,
+	method, url string,
+	data []byte,
+	headers map[string]string,
+	opts Options,
+) (*Response, error) {
+	response := &Response{StatusCode: -1}
+
+	transport, shared, err := c.transport(opts)
+	if err != nil {
+		return response, err
+	}
+	if !shared {
+		defer transport.CloseIdleConnections()
 	}
 
 	var client = &http.Client{
-		Timeout:   timeout,
-		Transport: transport,
+		Timeout:       opts.Timeout,
+		Transport:     transport,
+		CheckRedirect: opts.checkRedirect,
 	}
 
 	var req *http.Request
@@ -51,21 +126,51 @@ func Do(
 		)
 	}
 	if err != nil {
-		return nil, -1, err
+		return response, err
 	}
 
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+
+	start := time.Now()
+	var dnsStart, connectStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { response.Timings.DNS = time.Since(dnsStart) },
+		ConnectStart: func(string, string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			response.Timings.Connect = time.Since(connectStart)
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			response.Timings.TLS = time.Since(tlsStart)
+		},
+		GotConn:              func(info httptrace.GotConnInfo) { response.Reused = info.Reused },
+		GotFirstResponseByte: func() { response.Timings.TTFB = time.Since(start) },
+	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+
 	res, err := client.Do(req.WithContext(ctx))
 	if err != nil {
-		return nil, -1, err
+		response.Timings.Total = time.Since(start)
+		response.TLS = TLSInfoFromError(err)
+		return response, err
 	}
 	defer res.Body.Close()
-	body, err = io.ReadAll(res.Body)
+	if res.TLS != nil {
+		response.TLS = NewTLSInfo(res.TLS, opts.TLS, res.Request.URL.Hostname())
+	}
+	body, err := io.ReadAll(res.Body)
+	response.Timings.Total = time.Since(start)
 	if err != nil {
-		return nil, -1, err
+		return response, err
 	}
 
-	return body, res.StatusCode, nil
+	response.StatusCode = res.StatusCode
+	response.Header = res.Header
+	response.Body = body
+	return response, nil
 }
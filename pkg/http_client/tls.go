@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidCABundle   = errors.New("ca bundle contains no valid PEM certificate")
+	ErrInvalidClientCert = errors.New("client certificate and key must be a valid PEM pair")
+)
+
+// TLSOptions configures how the server certificate is verified and the client certificate
+// presented for mutual TLS, all PEM values are optional.
+type TLSOptions struct {
+	CABundle           string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+}
+
+// TLSInfo describes the certificate chain presented by the server, VerifyError is set when
+// the chain does not verify, including when verification was skipped for the request.
+type TLSInfo struct {
+	Version      string
+	Certificates []Certificate // leaf first
+	VerifyError  string
+}
+
+type Certificate struct {
+	Subject     string
+	Issuer      string
+	SANs        []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string // hex encoded sha256 of the DER certificate
+}
+
+// ValidateTLS checks that the PEM values of opts can be loaded.
+func ValidateTLS(opts TLSOptions) error {
+	_, err := opts.Config()
+	return err
+}
+
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+
+	roots, err := o.roots()
+	if err != nil {
+		return nil, err
+	}
+	config.RootCAs = roots
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(o.ClientCert), []byte(o.ClientKey))
+		if err != nil {
+			return nil, ErrInvalidClientCert
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// roots returns the pool of the CA bundle, or nil for the system pool.
+func (o TLSOptions) roots() (*x509.CertPool, error) {
+	if o.CABundle == "" {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(o.CABundle)) {
+		return nil, ErrInvalidCABundle
+	}
+	return pool, nil
+}
+
+// NewTLSInfo describes the chain of an established connection.
+func NewTLSInfo(state *tls.ConnectionState, opts TLSOptions, serverName string) *TLSInfo {
+	info := &TLSInfo{
+		Version:      tls.VersionName(state.Version),
+		Certificates: certificates(state.PeerCertificates),
+	}
+	if opts.InsecureSkipVerify && len(state.PeerCertificates) > 0 {
+		// the handshake did not verify the chain, do it here so that it is still reported
+		if err := verify(state.PeerCertificates, opts, serverName); err != nil {
+			info.VerifyError = err.Error()
+		}
+	}
+	return info
+}
+
+// TLSInfoFromError describes the chain rejected by a failed handshake, it returns nil when
+// err is not a certificate verification error.
+func TLSInfoFromError(err error) *TLSInfo {
+	var verifyErr *tls.CertificateVerificationError
+	if !errors.As(err, &verifyErr) {
+		return nil
+	}
+	return &TLSInfo{
+		Certificates: certificates(verifyErr.UnverifiedCertificates),
+		VerifyError:  verifyErr.Err.Error(),
+	}
+}
+
+func verify(chain []*x509.Certificate, opts TLSOptions, serverName string) error {
+	roots, err := opts.roots()
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+func certificates(chain []*x509.Certificate) []Certificate {
+	certs := make([]Certificate, 0, len(chain))
+	for _, cert := range chain {
+		sans := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		sans = append(sans, cert.EmailAddresses...)
+		for _, uri := range cert.URIs {
+			sans = append(sans, uri.String())
+		}
+
+		fingerprint := sha256.Sum256(cert.Raw)
+		certs = append(certs, Certificate{
+			Subject:     cert.Subject.String(),
+			Issuer:      cert.Issuer.String(),
+			SANs:        sans,
+			NotBefore:   cert.NotBefore,
+			NotAfter:    cert.NotAfter,
+			Fingerprint: hex.EncodeToString(fingerprint[:]),
+		})
+	}
+	return certs
+}
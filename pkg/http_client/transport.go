@@ -0,0 +1,229 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidProxy    = errors.New("proxy must be an http, https or socks5 url")
+	ErrInvalidResolver = errors.New("dns resolver must be a host:port address")
+)
+
+// PoolConfig tunes the connection pools of the shared transports, zero values use the
+// net/http defaults.
+type PoolConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DNSResolver         string // used by requests that don't set their own
+}
+
+// Client shares a transport, and so a connection pool, between requests that are sent
+// with the same connection settings. A transport is kept as long as an owner, such as a
+// health check agent, is bound to its settings; requests with settings no owner is bound to
+// are sent on a transport of their own that is closed afterwards.
+type Client struct {
+	pool       PoolConfig
+	mu         sync.Mutex
+	transports map[transportKey]*http.Transport
+	owners     map[uint]transportKey
+	refs       map[transportKey]int // owners bound to each key
+}
+
+// transportKey holds the options that are fixed for the lifetime of a transport.
+type transportKey struct {
+	proxy                 string
+	dnsResolver           string
+	connectTimeout        time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	tls                   TLSOptions
+}
+
+func New(pool PoolConfig) *Client {
+	if pool.MaxIdleConns == 0 {
+		pool.MaxIdleConns = 100
+	}
+	if pool.IdleConnTimeout == 0 {
+		pool.IdleConnTimeout = 90 * time.Second
+	}
+	return &Client{
+		pool:       pool,
+		transports: make(map[transportKey]*http.Transport),
+		owners:     make(map[uint]transportKey),
+		refs:       make(map[transportKey]int),
+	}
+}
+
+// Bind keeps the transport of opts while owner sends requests with them, the transport of
+// the options owner was bound to before is closed when no other owner uses it.
+func (c *Client) Bind(owner uint, opts Options) {
+	key := c.key(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.owners[owner]; ok {
+		if previous == key {
+			return
+		}
+		c.unref(previous)
+	}
+	c.owners[owner] = key
+	c.refs[key]++
+}
+
+// Release unbinds owner, its transport is closed when no other owner uses it.
+func (c *Client) Release(owner uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.owners[owner]; ok {
+		delete(c.owners, owner)
+		c.unref(key)
+	}
+}
+
+// unref drops a reference to key, the caller must hold mu.
+func (c *Client) unref(key transportKey) {
+	c.refs[key]--
+	if c.refs[key] > 0 {
+		return
+	}
+	delete(c.refs, key)
+	if transport, ok := c.transports[key]; ok {
+		transport.CloseIdleConnections()
+		delete(c.transports, key)
+	}
+}
+
+// Close releases the idle connections of all transports.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, transport := range c.transports {
+		transport.CloseIdleConnections()
+		delete(c.transports, key)
+	}
+}
+
+// ValidateProxy checks that proxy is empty or a url the transport can dial through.
+func ValidateProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return ErrInvalidProxy
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	}
+	return ErrInvalidProxy
+}
+
+// ValidateResolver checks that resolver is empty or a host:port address.
+func ValidateResolver(resolver string) error {
+	if resolver == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		return ErrInvalidResolver
+	}
+	return nil
+}
+
+func (c *Client) key(opts Options) transportKey {
+	key := transportKey{
+		proxy:                 opts.Proxy,
+		dnsResolver:           opts.DNSResolver,
+		connectTimeout:        opts.phase(opts.ConnectTimeout),
+		tlsHandshakeTimeout:   opts.phase(opts.TLSHandshakeTimeout),
+		responseHeaderTimeout: opts.phase(opts.ResponseHeaderTimeout),
+		tls:                   opts.TLS,
+	}
+	if key.dnsResolver == "" {
+		key.dnsResolver = c.pool.DNSResolver
+	}
+	return key
+}
+
+// transport returns the shared transport for opts, or a new one that the caller must close
+// when opts asks for a fresh connection or no owner is bound to opts.
+func (c *Client) transport(opts Options) (transport *http.Transport, shared bool, err error) {
+	key := c.key(opts)
+
+	if opts.FreshConnection {
+		transport, err := c.newTransport(key)
+		if err != nil {
+			return nil, false, err
+		}
+		transport.DisableKeepAlives = true
+		return transport, false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if transport, ok := c.transports[key]; ok {
+		return transport, true, nil
+	}
+	transport, err = c.newTransport(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.refs[key] == 0 {
+		return transport, false, nil
+	}
+	c.transports[key] = transport
+	return transport, true, nil
+}
+
+func (c *Client) newTransport(key transportKey) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout: key.connectTimeout,
+	}
+	if key.dnsResolver != "" {
+		if err := ValidateResolver(key.dnsResolver); err != nil {
+			return nil, err
+		}
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{Timeout: key.connectTimeout}).DialContext(ctx, network, key.dnsResolver)
+			},
+		}
+	}
+
+	tlsConfig, err := key.tls.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   key.tlsHandshakeTimeout,
+		ResponseHeaderTimeout: key.responseHeaderTimeout,
+		MaxIdleConns:          c.pool.MaxIdleConns,
+		MaxIdleConnsPerHost:   c.pool.MaxIdleConnsPerHost,
+		IdleConnTimeout:       c.pool.IdleConnTimeout,
+		ForceAttemptHTTP2:     true,
+	}
+	if key.proxy != "" {
+		if err := ValidateProxy(key.proxy); err != nil {
+			return nil, err
+		}
+		proxyURL, _ := url.Parse(key.proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport, nil
+}
@@ -0,0 +1,58 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientEvictsUnboundTransports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := New(PoolConfig{})
+	opts := Options{Timeout: time.Second}
+	other := Options{Timeout: 2 * time.Second}
+	send := func(opts Options) *Response {
+		t.Helper()
+		res, err := c.Do(context.Background(), http.MethodGet, server.URL, nil, nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	pooled := func() int {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.transports)
+	}
+
+	send(opts)
+	if pooled() != 0 {
+		t.Fatal("a transport without owner was kept")
+	}
+
+	c.Bind(1, opts)
+	c.Bind(2, opts)
+	send(opts)
+	if res := send(opts); !res.Reused || pooled() != 1 {
+		t.Fatalf("bound transport not shared, reused %v, pooled %d", res.Reused, pooled())
+	}
+
+	c.Release(1)
+	if pooled() != 1 {
+		t.Fatal("transport evicted while an owner is still bound")
+	}
+	c.Bind(2, other)
+	if pooled() != 0 {
+		t.Fatal("transport kept after its last owner moved to other options")
+	}
+
+	send(other)
+	c.Release(2)
+	if pooled() != 0 {
+		t.Fatal("transport kept after its last owner was released")
+	}
+}
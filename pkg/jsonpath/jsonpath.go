@@ -0,0 +1,93 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPath = errors.New("invalid json path")
+	ErrNotFound    = errors.New("json path not found")
+)
+
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// Validate reports whether path is supported by Lookup.
+func Validate(path string) error {
+	_, err := parse(path)
+	return err
+}
+
+// parse supports the dotted subset of JSONPath, e.g. "$.data.items[0].name".
+func parse(path string) ([]segment, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []segment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return nil, ErrInvalidPath
+		}
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			segments = append(segments, segment{key: key})
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, ErrInvalidPath
+			}
+			i, err := strconv.Atoi(idx)
+			if err != nil || i < 0 {
+				return nil, ErrInvalidPath
+			}
+			segments = append(segments, segment{index: i, isIdx: true})
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, ErrInvalidPath
+			}
+			rest = after[1:]
+		}
+	}
+	return segments, nil
+}
+
+// Lookup resolves path against a document decoded with encoding/json.
+func Lookup(doc any, path string) (any, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, seg := range segments {
+		if seg.isIdx {
+			arr, ok := current.([]any)
+			if !ok || seg.index >= len(arr) {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+			}
+			current = arr[seg.index]
+			continue
+		}
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		current, ok = obj[seg.key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "healthcheck"
+
+// Delivery outcomes, a failed delivery is retried until it is dead.
+const (
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+	DeliveryDead      = "dead"
+)
+
+// Metrics holds the prometheus collectors of the service on a registry of its own, together
+// with the Go runtime and process collectors.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	endpointUp                  *prometheus.GaugeVec
+	endpointLatency             *prometheus.GaugeVec
+	endpointConsecutiveFailures *prometheus.GaugeVec
+	checkDuration               *prometheus.HistogramVec
+	checks                      *prometheus.CounterVec
+	checkFailures               *prometheus.CounterVec
+	deliveries                  *prometheus.CounterVec
+	httpRequests                *prometheus.CounterVec
+	httpRequestDuration         *prometheus.HistogramVec
+
+	mu sync.Mutex
+	// endpoints holds the labels each endpoint's gauges were last set with, so that the old
+	// series are dropped when the name or url of an endpoint changes
+	endpoints map[uint]prometheus.Labels
+}
+
+// CheckResult describes a finished check and the endpoint state after it.
+type CheckResult struct {
+	EndpointID          uint
+	Name                string
+	URL                 string
+	CheckType           string
+	Up                  bool
+	Duration            time.Duration
+	ConsecutiveFailures int
+	ErrorClass          string // empty for a successful check
+}
+
+func New() *Metrics {
+	endpointLabels := []string{"endpoint_id", "name", "url"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		endpointUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "endpoint_up",
+			Help:      "Whether the endpoint is healthy (1) or not (0).",
+		}, endpointLabels),
+		endpointLatency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "endpoint_last_latency_seconds",
+			Help:      "Duration of the latest check of the endpoint.",
+		}, endpointLabels),
+		endpointConsecutiveFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "endpoint_consecutive_failures",
+			Help:      "Number of failed checks of the endpoint since the last successful one.",
+		}, endpointLabels),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_duration_seconds",
+			Help:      "Duration of checks.",
+			Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"endpoint_id", "check_type"}),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "checks_total",
+			Help:      "Number of checks run.",
+		}, []string{"endpoint_id", "check_type"}),
+		checkFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_failures_total",
+			Help:      "Number of failed checks by error class.",
+		}, []string{"endpoint_id", "check_type", "error_class"}),
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notification_deliveries_total",
+			Help:      "Number of notification delivery attempts by outcome.",
+		}, []string{"outcome"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of API requests served.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of API requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		endpoints: make(map[uint]prometheus.Labels),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.endpointUp,
+		m.endpointLatency,
+		m.endpointConsecutiveFailures,
+		m.checkDuration,
+		m.checks,
+		m.checkFailures,
+		m.deliveries,
+		m.httpRequests,
+		m.httpRequestDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format, they span every team.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterAgents exports the number of active and inactive agents as reported by count on
+// every scrape.
+func (m *Metrics) RegisterAgents(count func() (active, inactive int)) {
+	help := "Number of health check agents by state."
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "agents",
+			Help:        help,
+			ConstLabels: prometheus.Labels{"state": "active"},
+		}, func() float64 {
+			active, _ := count()
+			return float64(active)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "agents",
+			Help:        help,
+			ConstLabels: prometheus.Labels{"state": "inactive"},
+		}, func() float64 {
+			_, inactive := count()
+			return float64(inactive)
+		}),
+	)
+}
+
+func (m *Metrics) ObserveCheck(result CheckResult) {
+	id := strconv.FormatUint(uint64(result.EndpointID), 10)
+	labels := prometheus.Labels{"endpoint_id": id, "name": result.Name, "url": result.URL}
+
+	m.mu.Lock()
+	if previous, ok := m.endpoints[result.EndpointID]; ok && (previous["name"] != result.Name || previous["url"] != result.URL) {
+		m.deleteEndpointGauges(previous)
+	}
+	m.endpoints[result.EndpointID] = labels
+	m.mu.Unlock()
+
+	up := 0.0
+	if result.Up {
+		up = 1
+	}
+	m.endpointUp.With(labels).Set(up)
+	m.endpointLatency.With(labels).Set(result.Duration.Seconds())
+	m.endpointConsecutiveFailures.With(labels).Set(float64(result.ConsecutiveFailures))
+
+	m.checkDuration.WithLabelValues(id, result.CheckType).Observe(result.Duration.Seconds())
+	m.checks.WithLabelValues(id, result.CheckType).Inc()
+	if result.ErrorClass != "" {
+		m.checkFailures.WithLabelValues(id, result.CheckType, result.ErrorClass).Inc()
+	}
+}
+
+// RemoveEndpoint drops the series of an endpoint that is no longer checked.
+func (m *Metrics) RemoveEndpoint(endpointID uint) {
+	m.mu.Lock()
+	labels, ok := m.endpoints[endpointID]
+	delete(m.endpoints, endpointID)
+	m.mu.Unlock()
+	if ok {
+		m.deleteEndpointGauges(labels)
+	}
+
+	id := prometheus.Labels{"endpoint_id": strconv.FormatUint(uint64(endpointID), 10)}
+	m.checkDuration.DeletePartialMatch(id)
+	m.checks.DeletePartialMatch(id)
+	m.checkFailures.DeletePartialMatch(id)
+}
+
+func (m *Metrics) deleteEndpointGauges(labels prometheus.Labels) {
+	m.endpointUp.Delete(labels)
+	m.endpointLatency.Delete(labels)
+	m.endpointConsecutiveFailures.Delete(labels)
+}
+
+func (m *Metrics) ObserveDelivery(outcome string) {
+	m.deliveries.WithLabelValues(outcome).Inc()
+}
+
+// ObserveHTTPRequest records an API request, route is the route pattern rather than the path
+// to keep the number of series bounded.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
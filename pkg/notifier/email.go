@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func NewEmailNotifier(host string, port int, username, password, from string, to []string) *EmailNotifier {
+	return &EmailNotifier{host, port, username, password, from, to}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	subject := fmt.Sprintf("[healthcheck] %s is %s", notification.URL, notification.StatusText())
+	if notification.Certificate != nil {
+		subject = fmt.Sprintf("[healthcheck] certificate of %s is %s", notification.URL, notification.Certificate.Reason)
+	}
+	msg := strings.Join([]string{
+		"From: " + headerValue(n.From),
+		"To: " + headerValue(strings.Join(n.To, ", ")),
+		"Subject: " + headerValue(subject),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		notification.Summary(),
+	}, "\r\n")
+
+	return n.send(ctx, []byte(msg))
+}
+
+// send does what smtp.SendMail does on a connection bounded by ctx, a stalled server fails
+// the delivery rather than blocking it.
+func (n *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(n.Host, strconv.Itoa(n.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// headerValue drops the line breaks of a header value, which would start another header.
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type Notification struct {
+	EndpointID     uint
+	URL            string
+	Method         string
+	PreviousStatus bool
+	Status         bool
+	ChangedAt      time.Time
+	LastStatusCode int
+	Latency        float64 // in milliseconds
+	FailureReason  string
+	IncidentID     uint
+	// Certificate is set on warnings about the TLS certificate of the endpoint, which
+	// leave its status unchanged.
+	Certificate *CertificateWarning
+}
+
+type CertificateWarningReason string
+
+const (
+	CertificateExpiring CertificateWarningReason = "expiring"
+	CertificateInvalid  CertificateWarningReason = "invalid"
+)
+
+type CertificateWarning struct {
+	Reason          CertificateWarningReason
+	Subject         string
+	Issuer          string
+	NotAfter        time.Time
+	DaysUntilExpiry int
+	VerifyError     string
+}
+
+func statusText(status bool) string {
+	if status {
+		return "up"
+	}
+	return "down"
+}
+
+func (n Notification) StatusText() string {
+	return statusText(n.Status)
+}
+
+// Event names the notification, e.g. endpoint.down or certificate.expiring.
+func (n Notification) Event() string {
+	if n.Certificate != nil {
+		return "certificate." + string(n.Certificate.Reason)
+	}
+	return "endpoint." + n.StatusText()
+}
+
+func (n Notification) Summary() string {
+	if c := n.Certificate; c != nil {
+		if c.Reason == CertificateInvalid {
+			return fmt.Sprintf("certificate of endpoint %d (%s) is invalid: %s", n.EndpointID, n.URL, c.VerifyError)
+		}
+		return fmt.Sprintf("certificate of endpoint %d (%s) expires in %d days on %s",
+			n.EndpointID, n.URL, c.DaysUntilExpiry, c.NotAfter.Format(time.DateOnly))
+	}
+	summary := fmt.Sprintf("endpoint %d (%s %s) is %s", n.EndpointID, n.Method, n.URL, n.StatusText())
+	if !n.Status && n.FailureReason != "" {
+		summary += ": " + n.FailureReason
+	}
+	return summary
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+func postJSON(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, url, body, nil)
+}
+
+func post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier sends PagerDuty Events API v2 events, triggering an alert when an endpoint
+// goes down and resolving it once the endpoint recovers. Certificate warnings trigger a
+// separate warning alert.
+type PagerDutyNotifier struct {
+	URL        string
+	RoutingKey string
+}
+
+func NewPagerDutyNotifier(url, routingKey string) *PagerDutyNotifier {
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+	return &PagerDutyNotifier{url, routingKey}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+func (n *PagerDutyNotifier) Notify(ctx context.Context, notification Notification) error {
+	event := pagerDutyEvent{
+		RoutingKey:  n.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    fmt.Sprintf("healthcheck-endpoint-%d", notification.EndpointID),
+		Payload: &pagerDutyPayload{
+			Summary:   notification.Summary(),
+			Source:    notification.URL,
+			Severity:  "critical",
+			Timestamp: notification.ChangedAt.Format(time.RFC3339),
+			CustomDetails: map[string]any{
+				"method":           notification.Method,
+				"last_status_code": notification.LastStatusCode,
+				"latency_ms":       notification.Latency,
+				"failure_reason":   notification.FailureReason,
+				"incident_id":      notification.IncidentID,
+			},
+		},
+	}
+	if c := notification.Certificate; c != nil {
+		event.DedupKey = fmt.Sprintf("healthcheck-certificate-%d", notification.EndpointID)
+		event.Payload.Severity = "warning"
+		event.Payload.CustomDetails = map[string]any{
+			"subject":           c.Subject,
+			"issuer":            c.Issuer,
+			"not_after":         c.NotAfter.Format(time.RFC3339),
+			"days_until_expiry": c.DaysUntilExpiry,
+			"verify_error":      c.VerifyError,
+		}
+	} else if notification.Status {
+		event.EventAction = "resolve"
+		event.Payload = nil
+	}
+
+	return postJSON(ctx, n.URL, event)
+}
@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+)
+
+// SlackNotifier posts to a Slack compatible incoming webhook.
+type SlackNotifier struct {
+	URL string
+}
+
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url}
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	icon := ":red_circle:"
+	if notification.Certificate != nil {
+		icon = ":warning:"
+	} else if notification.Status {
+		icon = ":large_green_circle:"
+	}
+
+	payload := struct {
+		Text string `json:"text"`
+	}{
+		Text: fmt.Sprintf("%s %s", icon, notification.Summary()),
+	}
+
+	return postJSON(ctx, n.URL, payload)
+}
@@ -0,0 +1,41 @@
+package notifier
+
+import "context"
+
+// TeamsNotifier posts a message card to a Microsoft Teams incoming webhook.
+type TeamsNotifier struct {
+	URL string
+}
+
+func NewTeamsNotifier(url string) *TeamsNotifier {
+	return &TeamsNotifier{url}
+}
+
+func (n *TeamsNotifier) Notify(ctx context.Context, notification Notification) error {
+	color := "D70000"
+	title := "Health check " + notification.StatusText()
+	if notification.Certificate != nil {
+		color = "FFA500"
+		title = "Certificate " + string(notification.Certificate.Reason)
+	} else if notification.Status {
+		color = "2DC72D"
+	}
+
+	payload := struct {
+		Type       string `json:"@type"`
+		Context    string `json:"@context"`
+		ThemeColor string `json:"themeColor"`
+		Summary    string `json:"summary"`
+		Title      string `json:"title"`
+		Text       string `json:"text"`
+	}{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: color,
+		Summary:    notification.Summary(),
+		Title:      title,
+		Text:       notification.Summary(),
+	}
+
+	return postJSON(ctx, n.URL, payload)
+}
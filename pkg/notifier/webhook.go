@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const WebhookSchemaVersion = "2"
+
+// WebhookNotifier posts the notification to {URL}/{endpoint id}, signing it when a secret is set.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+}
+
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{url, secret}
+}
+
+type WebhookPayload struct {
+	SchemaVersion  string              `json:"schema_version"`
+	Event          string              `json:"event"`
+	Endpoint       WebhookEndpoint     `json:"endpoint"`
+	PreviousStatus bool                `json:"previous_status"`
+	Status         bool                `json:"status"`
+	ChangedAt      time.Time           `json:"changed_at"`
+	SentAt         time.Time           `json:"sent_at"`
+	LastStatusCode int                 `json:"last_status_code"`
+	Latency        float64             `json:"latency_ms"`
+	FailureReason  string              `json:"failure_reason,omitempty"`
+	IncidentID     uint                `json:"incident_id,omitempty"`
+	Certificate    *WebhookCertificate `json:"certificate,omitempty"`
+}
+
+type WebhookCertificate struct {
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	NotAfter        time.Time `json:"not_after"`
+	DaysUntilExpiry int       `json:"days_until_expiry"`
+	VerifyError     string    `json:"verify_error,omitempty"`
+}
+
+type WebhookEndpoint struct {
+	ID     uint   `json:"id"`
+	URL    string `json:"url"`
+	Method string `json:"method"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	now := time.Now()
+	payload := WebhookPayload{
+		SchemaVersion: WebhookSchemaVersion,
+		Event:         notification.Event(),
+		Endpoint: WebhookEndpoint{
+			ID:     notification.EndpointID,
+			URL:    notification.URL,
+			Method: notification.Method,
+		},
+		PreviousStatus: notification.PreviousStatus,
+		Status:         notification.Status,
+		ChangedAt:      notification.ChangedAt,
+		SentAt:         now,
+		LastStatusCode: notification.LastStatusCode,
+		Latency:        notification.Latency,
+		FailureReason:  notification.FailureReason,
+		IncidentID:     notification.IncidentID,
+	}
+	if c := notification.Certificate; c != nil {
+		payload.Certificate = &WebhookCertificate{
+			Subject:         c.Subject,
+			Issuer:          c.Issuer,
+			NotAfter:        c.NotAfter,
+			DaysUntilExpiry: c.DaysUntilExpiry,
+			VerifyError:     c.VerifyError,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"X-Healthcheck-Schema-Version": WebhookSchemaVersion}
+	if n.Secret != "" {
+		timestamp := now.Unix()
+		headers[TimestampHeader] = strconv.FormatInt(timestamp, 10)
+		headers[SignatureHeader] = Sign(n.Secret, timestamp, body)
+	}
+
+	return post(ctx, fmt.Sprintf("%s/%v", n.URL, notification.EndpointID), body, headers)
+}
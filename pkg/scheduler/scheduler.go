@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type Job func(ctx context.Context)
+
+// Scheduler runs periodic jobs on a bounded pool of workers. Jobs are kept in a min-heap
+// ordered by their next run, runs are scheduled relative to the previous scheduled time so
+// they don't drift by the job duration, and jobs with the same id never run concurrently; ticks
+// that come due while the previous run is still in progress are skipped.
+type Scheduler struct {
+	mu      sync.Mutex
+	queue   jobQueue
+	entries map[uint]*entry
+	running map[uint]bool // ids with a run in progress, kept across Remove and Add
+	wake    chan struct{}
+	work    chan *entry
+	workers int
+}
+
+type entry struct {
+	id       uint
+	interval time.Duration
+	job      Job
+	next     time.Time
+	index    int
+}
+
+// minInterval guards against busy looping on jobs added with a non-positive interval.
+const minInterval = time.Second
+
+func New(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Scheduler{
+		entries: make(map[uint]*entry),
+		running: make(map[uint]bool),
+		wake:    make(chan struct{}, 1),
+		work:    make(chan *entry),
+		workers: workers,
+	}
+}
+
+// Run dispatches due jobs to the workers until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, wg *sync.WaitGroup) {
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go s.worker(ctx, wg)
+	}
+
+	wg.Add(1)
+	go s.dispatch(ctx, wg)
+}
+
+// Add schedules a job, its first run is randomly delayed by up to one interval so jobs
+// added at the same time, e.g. after a restart, don't fire in lockstep.
+func (s *Scheduler) Add(id uint, interval time.Duration, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[id]; ok {
+		heap.Remove(&s.queue, e.index)
+		delete(s.entries, id)
+	}
+
+	interval = max(interval, minInterval)
+	e := &entry{
+		id:       id,
+		interval: interval,
+		job:      job,
+		next:     time.Now().Add(jitter(interval)),
+	}
+	s.entries[id] = e
+	heap.Push(&s.queue, e)
+	s.notify()
+}
+
+// Reschedule changes the interval of a job, keeping its current next run unless it is
+// further away than the new interval.
+func (s *Scheduler) Reschedule(id uint, interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return false
+	}
+	interval = max(interval, minInterval)
+	e.interval = interval
+	if latest := time.Now().Add(interval); e.next.After(latest) {
+		e.next = latest
+		heap.Fix(&s.queue, e.index)
+		s.notify()
+	}
+	return true
+}
+
+// Remove unschedules a job, a run in progress is left to complete and a job added again with
+// the same id does not run before it has.
+func (s *Scheduler) Remove(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return
+	}
+	heap.Remove(&s.queue, e.index)
+	delete(s.entries, id)
+}
+
+// Next returns the next scheduled run of a job.
+func (s *Scheduler) Next(id uint) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.next, true
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) dispatch(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		e, wait := s.due(time.Now())
+		if e != nil {
+			select {
+			case s.work <- e:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// due pops the next job to run, or returns how long to wait for one.
+func (s *Scheduler) due(now time.Time) (*entry, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.queue) > 0 {
+		e := s.queue[0]
+		if e.next.After(now) {
+			return nil, e.next.Sub(now)
+		}
+
+		for !e.next.After(now) {
+			e.next = e.next.Add(e.interval)
+		}
+		heap.Fix(&s.queue, e.index)
+
+		if s.running[e.id] {
+			continue
+		}
+		s.running[e.id] = true
+		return e, 0
+	}
+	return nil, time.Hour
+}
+
+func (s *Scheduler) worker(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.work:
+			e.job(ctx)
+			s.mu.Lock()
+			delete(s.running, e.id)
+			s.mu.Unlock()
+		}
+	}
+}
+
+func jitter(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(interval)))
+}
+
+type jobQueue []*entry
+
+func (q jobQueue) Len() int           { return len(q) }
+func (q jobQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *jobQueue) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// makeDue moves the next run of a job to now so that tests don't wait for the jitter.
+func makeDue(s *Scheduler, id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[id]; ok {
+		e.next = time.Now()
+		heap.Fix(&s.queue, e.index)
+		s.notify()
+	}
+}
+
+func start(t *testing.T, workers int) *Scheduler {
+	t.Helper()
+	s := New(workers)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	s.Run(ctx, wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return s
+}
+
+func TestSchedulerConcurrentAddRemove(t *testing.T) {
+	const ids = 8
+	s := start(t, 4)
+
+	var inFlight [ids]atomic.Int32
+	job := func(id uint) Job {
+		return func(ctx context.Context) {
+			if inFlight[id].Add(1) > 1 {
+				t.Errorf("job %d ran concurrently with itself", id)
+			}
+			time.Sleep(time.Millisecond)
+			inFlight[id].Add(-1)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				id := uint((g + i) % ids)
+				switch i % 5 {
+				case 0, 1:
+					s.Add(id, time.Second, job(id))
+					makeDue(s, id)
+				case 2:
+					s.Reschedule(id, 2*time.Second)
+				case 3:
+					s.Next(id)
+				case 4:
+					s.Remove(id)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for id := uint(0); id < ids; id++ {
+		s.Remove(id)
+	}
+	if _, ok := s.Next(0); ok {
+		t.Fatal("removed job is still scheduled")
+	}
+
+	// the scheduler still runs jobs once the racing is over
+	ran := make(chan struct{})
+	var once sync.Once
+	s.Add(0, time.Second, func(ctx context.Context) { once.Do(func() { close(ran) }) })
+	makeDue(s, 0)
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job added after the concurrent changes did not run")
+	}
+}
+
+func TestSchedulerReAddWaitsForRunningJob(t *testing.T) {
+	s := start(t, 2)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s.Add(1, time.Second, func(ctx context.Context) {
+		close(started)
+		<-release
+	})
+	makeDue(s, 1)
+	<-started
+
+	rerun := make(chan struct{}, 1)
+	s.Remove(1)
+	s.Add(1, time.Second, func(ctx context.Context) {
+		select {
+		case rerun <- struct{}{}:
+		default:
+		}
+	})
+	makeDue(s, 1)
+
+	select {
+	case <-rerun:
+		t.Fatal("job added again ran while the removed run was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	makeDue(s, 1)
+	select {
+	case <-rerun:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job added again did not run after the removed run completed")
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"healthcheck/internal/model"
+	httpclient "healthcheck/pkg/http_client"
+	"healthcheck/pkg/jsonpath"
+	"net/http"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+type assertionError struct {
+	assertion string
+	class     model.CheckErrorClass
+	reason    string
+}
+
+func (e *assertionError) Error() string {
+	return fmt.Sprintf("assertion %s failed: %s", e.assertion, e.reason)
+}
+
+func newAssertionError(assertion, reason string) *assertionError {
+	return &assertionError{assertion, model.CheckErrorAssertion, reason}
+}
+
+func evaluateCriteria(criteria *model.SuccessCriteria, res *httpclient.Response) error {
+	if !criteria.AcceptsStatusCode(res.StatusCode) {
+		return &assertionError{"status_code", model.CheckErrorNon2xx, fmt.Sprintf("unexpected status code %d", res.StatusCode)}
+	}
+
+	for key, value := range criteria.Headers {
+		actual, ok := res.Header[http.CanonicalHeaderKey(key)]
+		if !ok {
+			return newAssertionError("header:"+key, "header is missing")
+		}
+		if value != "" && (len(actual) == 0 || actual[0] != value) {
+			return newAssertionError("header:"+key, fmt.Sprintf("expected %q, got %q", value, actual))
+		}
+	}
+
+	return evaluateContent(criteria, res)
+}
+
+// evaluateContent checks the assertions that apply to every check type, leaving out the
+// http status code and headers.
+func evaluateContent(criteria *model.SuccessCriteria, res *httpclient.Response) error {
+	if criteria.BodyContains != "" && !bytes.Contains(res.Body, []byte(criteria.BodyContains)) {
+		return newAssertionError("body_contains", fmt.Sprintf("body does not contain %q", criteria.BodyContains))
+	}
+
+	if criteria.BodyRegex != "" {
+		re, err := regexp.Compile(criteria.BodyRegex)
+		if err != nil {
+			return newAssertionError("body_regex", err.Error())
+		}
+		if !re.Match(res.Body) {
+			return newAssertionError("body_regex", fmt.Sprintf("body does not match %q", criteria.BodyRegex))
+		}
+	}
+
+	if len(criteria.JSONPath) > 0 {
+		var doc any
+		if err := json.Unmarshal(res.Body, &doc); err != nil {
+			return newAssertionError("json_path", "body is not valid json")
+		}
+		for _, assertion := range criteria.JSONPath {
+			actual, err := jsonpath.Lookup(doc, assertion.Path)
+			if err != nil {
+				return newAssertionError("json_path:"+assertion.Path, err.Error())
+			}
+			if assertion.Value != nil && !jsonEqual(actual, assertion.Value) {
+				return newAssertionError("json_path:"+assertion.Path, fmt.Sprintf("expected %v, got %v", assertion.Value, actual))
+			}
+		}
+	}
+
+	if criteria.MaxLatency > 0 {
+		max := time.Duration(criteria.MaxLatency) * time.Millisecond
+		if res.Timings.Total > max {
+			return newAssertionError("max_latency", fmt.Sprintf("took %v, max %v", res.Timings.Total, max))
+		}
+	}
+
+	return nil
+}
+
+// jsonEqual compares values after normalizing them through encoding/json,
+// so an expected int matches the float64 produced by decoding the body.
+func jsonEqual(a, b any) bool {
+	var na, nb any
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if json.Unmarshal(ja, &na) != nil || json.Unmarshal(jb, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
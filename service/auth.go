@@ -0,0 +1,315 @@
+package service
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/pkg/auth"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// apiKeyTouchInterval bounds how often the last use of an API key is written.
+const apiKeyTouchInterval = time.Minute
+
+var (
+	ErrUnauthenticated    = errors.New("missing or invalid credentials")
+	ErrInvalidCredentials = errors.New("invalid name or password")
+	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
+	ErrProbeUser          = errors.New("the probe role is only granted to API keys")
+	ErrProbeTeam          = errors.New("probe API keys belong to the default team")
+)
+
+// Principal is who a request is made by.
+type Principal struct {
+	Name     string     `json:"name"`
+	Role     model.Role `json:"role"`
+	Kind     string     `json:"kind"` // user or api_key
+	TeamID   uint       `json:"team_id"`
+	Operator bool       `json:"operator"` // admin of the default team, manages every team
+}
+
+// Scope restricts what the principal can see to its team.
+func (p *Principal) Scope() repository.Scope {
+	return repository.TeamScope(p.TeamID)
+}
+
+type AuthService interface {
+	// Authenticate resolves an API key or a token issued by Login.
+	Authenticate(credential string) (*Principal, error)
+	Login(name, password string) (token string, expiresAt time.Time, err error)
+	// CreateUser and CreateAPIKey create in the team of the scope.
+	CreateUser(scope repository.Scope, name, password, role string) (*model.User, error)
+	FetchAllUsers(scope repository.Scope) ([]*model.User, error)
+	DeleteUser(scope repository.Scope, id uint) error
+	// CreateAPIKey returns the key, it cannot be retrieved afterwards. Probe keys see the
+	// endpoints of every team and are only created in the default team.
+	CreateAPIKey(scope repository.Scope, name, role string, expiresAt *time.Time, createdBy string) (*model.APIKey, string, error)
+	FetchAllAPIKeys(scope repository.Scope) ([]*model.APIKey, error)
+	RevokeAPIKey(scope repository.Scope, id uint) error
+}
+
+type authService struct {
+	disabled      bool
+	jwtSecret     []byte
+	tokenTTL      time.Duration
+	defaultTeamID uint
+	teamRepo      repository.TeamRepository
+	userRepo      repository.UserRepository
+	apiKeyRepo    repository.APIKeyRepository
+}
+
+// NewAuthService creates the auth service and the admin user of the default team when it does
+// not exist yet, with a generated password that is written once to stderr when adminPassword
+// is empty. With disabled every request is made by an anonymous admin of the default team.
+func NewAuthService(
+	disabled bool,
+	jwtSecret []byte,
+	tokenTTL time.Duration,
+	adminName, adminPassword string,
+	defaultTeamID uint,
+	teamRepo repository.TeamRepository,
+	userRepo repository.UserRepository,
+	apiKeyRepo repository.APIKeyRepository,
+) (AuthService, error) {
+	if disabled {
+		log.Println("authentication is disabled, every request is made as an admin")
+	}
+	if len(jwtSecret) == 0 {
+		secret, err := auth.RandomSecret(32)
+		if err != nil {
+			return nil, err
+		}
+		jwtSecret = secret
+		log.Println("no token secret configured, tokens will not survive a restart")
+	}
+
+	authService := &authService{disabled, jwtSecret, tokenTTL, defaultTeamID, teamRepo, userRepo, apiKeyRepo}
+	if adminName != "" {
+		if err := authService.ensureAdmin(adminName, adminPassword); err != nil {
+			return nil, err
+		}
+	}
+	return authService, nil
+}
+
+func (s *authService) ensureAdmin(name, password string) error {
+	_, err := s.userRepo.FetchByName(name)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	generated := password == ""
+	if generated {
+		secret, err := auth.RandomSecret(12)
+		if err != nil {
+			return err
+		}
+		password = hex.EncodeToString(secret)
+	}
+	if _, err := s.CreateUser(repository.TeamScope(s.defaultTeamID), name, password, string(model.RoleAdmin)); err != nil {
+		return err
+	}
+	log.Println("admin user", name, "created")
+	if generated {
+		// written straight to the terminal rather than through the log, which may be shipped
+		// and kept elsewhere
+		fmt.Fprintf(os.Stderr, "\nWARNING: generated password of admin user %s, shown only this once: %s\n"+
+			"Store it now or set ADMIN_PASSWORD, it cannot be retrieved later.\n\n", name, password)
+	}
+	return nil
+}
+
+func (s *authService) Authenticate(credential string) (*Principal, error) {
+	if s.disabled {
+		return s.principal("anonymous", model.RoleAdmin, "user", s.defaultTeamID), nil
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	if strings.HasPrefix(credential, auth.APIKeyPrefix) {
+		return s.authenticateAPIKey(credential)
+	}
+
+	claims, err := auth.ParseToken(s.jwtSecret, credential, time.Now())
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	// the user is looked up so that deleted users and role changes take effect right away
+	user, err := s.userRepo.FetchByName(claims.Subject)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.principal(user.Name, user.Role, "user", user.TeamID), nil
+}
+
+func (s *authService) authenticateAPIKey(key string) (*Principal, error) {
+	keyID, ok := auth.APIKeyID(key)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	apiKey, err := s.apiKeyRepo.FetchByKeyID(keyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(auth.HashAPIKey(key))) != 1 || !apiKey.Usable(now) {
+		return nil, ErrUnauthenticated
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(apiKey.ID, now); err != nil {
+			log.Println("failed to update last use of api key ", apiKey.ID, ", err:", err.Error())
+		}
+	}
+	return s.principal(apiKey.Name, apiKey.Role, "api_key", apiKey.TeamID), nil
+}
+
+func (s *authService) principal(name string, role model.Role, kind string, teamID uint) *Principal {
+	return &Principal{
+		Name:     name,
+		Role:     role,
+		Kind:     kind,
+		TeamID:   teamID,
+		Operator: teamID == s.defaultTeamID && role.Allows(model.RoleAdmin),
+	}
+}
+
+func (s *authService) Login(name, password string) (string, time.Time, error) {
+	user, err := s.userRepo.FetchByName(name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.tokenTTL)
+	token, err := auth.SignToken(s.jwtSecret, auth.Claims{
+		Subject:   user.Name,
+		Role:      string(user.Role),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+func (s *authService) CreateUser(scope repository.Scope, name, password, role string) (*model.User, error) {
+	if err := model.Role(role).Validate(); err != nil {
+		return nil, err
+	}
+	if model.Role(role) == model.RoleProbe {
+		return nil, ErrProbeUser
+	}
+	if _, err := s.teamRepo.FetchByID(scope.TeamID); err != nil {
+		return nil, err
+	}
+	if len(password) < 8 {
+		return nil, ErrPasswordTooShort
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{TeamID: scope.TeamID, Name: name, PasswordHash: hash, Role: model.Role(role)}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *authService) FetchAllUsers(scope repository.Scope) ([]*model.User, error) {
+	users, err := s.userRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *authService) DeleteUser(scope repository.Scope, id uint) error {
+	if _, err := s.userRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) CreateAPIKey(scope repository.Scope, name, role string, expiresAt *time.Time, createdBy string) (*model.APIKey, string, error) {
+	if err := model.Role(role).Validate(); err != nil {
+		return nil, "", err
+	}
+	if model.Role(role) == model.RoleProbe && scope.TeamID != s.defaultTeamID {
+		return nil, "", ErrProbeTeam
+	}
+	if _, err := s.teamRepo.FetchByID(scope.TeamID); err != nil {
+		return nil, "", err
+	}
+	key, keyID, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &model.APIKey{
+		TeamID:    scope.TeamID,
+		Name:      name,
+		KeyID:     keyID,
+		KeyHash:   auth.HashAPIKey(key),
+		Role:      model.Role(role),
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+func (s *authService) FetchAllAPIKeys(scope repository.Scope) ([]*model.APIKey, error) {
+	apiKeys, err := s.apiKeyRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (s *authService) RevokeAPIKey(scope repository.Scope, id uint) error {
+	if _, err := s.apiKeyRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.apiKeyRepo.Revoke(id, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"healthcheck/internal/model"
+	httpclient "healthcheck/pkg/http_client"
+	"healthcheck/pkg/notifier"
+	"log"
+	"time"
+)
+
+// recordCertificate stores the certificate chain seen by a check and sends a warning when the
+// leaf certificate is about to expire or the chain does not verify. A warning is sent once per
+// certificate and reason.
+func (s *endpointService) recordCertificate(agent *model.HealthCheckAgent, endpoint *model.Endpoint, info *httpclient.TLSInfo) {
+	if len(info.Certificates) == 0 {
+		return
+	}
+
+	previous := agent.Certificate
+	if previous == nil {
+		previous, _ = s.certificateRepo.FetchByEndpointID(endpoint.ID)
+	}
+
+	now := time.Now()
+	leaf := info.Certificates[0]
+	sans, _ := json.Marshal(leaf.SANs)
+	chain, _ := json.Marshal(info.Certificates)
+	certificate := &model.TLSCertificate{
+		EndpointID:  endpoint.ID,
+		Subject:     leaf.Subject,
+		Issuer:      leaf.Issuer,
+		SANs:        string(sans),
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+		Fingerprint: leaf.Fingerprint,
+		Chain:       string(chain),
+		VerifyError: info.VerifyError,
+		CheckedAt:   now,
+	}
+
+	var reason notifier.CertificateWarningReason
+	if certificate.VerifyError != "" {
+		reason = notifier.CertificateInvalid
+	} else if certificate.ExpiresInDays(now) <= s.certificateWarningDays {
+		reason = notifier.CertificateExpiring
+	}
+	certificate.Warning = string(reason)
+
+	if reason != "" && (previous == nil || previous.Warning != certificate.Warning || previous.Fingerprint != certificate.Fingerprint) {
+		s.notificationService.Notify(notifier.Notification{
+			EndpointID:     endpoint.ID,
+			URL:            endpoint.URL,
+			Method:         string(endpoint.HTTPMethod),
+			PreviousStatus: agent.LastStatus(),
+			Status:         agent.LastStatus(),
+			ChangedAt:      now,
+			Certificate: &notifier.CertificateWarning{
+				Reason:          reason,
+				Subject:         certificate.Subject,
+				Issuer:          certificate.Issuer,
+				NotAfter:        certificate.NotAfter,
+				DaysUntilExpiry: certificate.ExpiresInDays(now),
+				VerifyError:     certificate.VerifyError,
+			},
+		})
+	}
+
+	if err := s.certificateRepo.Save(certificate); err != nil {
+		log.Println("failed to save tls certificate for endpoint ", endpoint.ID, ", err:", err.Error())
+		return
+	}
+	agent.Certificate = certificate
+}
+
+// setCertificateExpiry fills in the days left before the certificate of the endpoint expires.
+func setCertificateExpiry(endpoint *model.Endpoint, now time.Time) {
+	if endpoint.Certificate != nil {
+		endpoint.Certificate.DaysUntilExpiry = endpoint.Certificate.ExpiresInDays(now)
+	}
+}
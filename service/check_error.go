@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"healthcheck/internal/model"
+	"net"
+	"syscall"
+)
+
+func classifyCheckError(err error) model.CheckErrorClass {
+	if err == nil {
+		return model.CheckErrorNone
+	}
+
+	var assertionErr *assertionError
+	if errors.As(err, &assertionErr) {
+		return assertionErr.class
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return model.CheckErrorDNS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return model.CheckErrorTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return model.CheckErrorConnectionRefused
+	}
+
+	var (
+		certVerifyErr   *tls.CertificateVerificationError
+		recordHeaderErr tls.RecordHeaderError
+		alertErr        tls.AlertError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		certificateErr  x509.CertificateInvalidError
+	)
+	if errors.As(err, &certVerifyErr) || errors.As(err, &recordHeaderErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthErr) || errors.As(err, &hostnameErr) || errors.As(err, &certificateErr) {
+		return model.CheckErrorTLS
+	}
+
+	return model.CheckErrorUnknown
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/pkg/checker"
+	httpclient "healthcheck/pkg/http_client"
+	"time"
+)
+
+// newChecker returns the checker of a non http endpoint.
+func newChecker(endpoint *model.Endpoint) (checker.Checker, error) {
+	switch endpoint.CheckType {
+	case model.CheckTCP:
+		return checker.NewTCPChecker(), nil
+	case model.CheckDNS:
+		return checker.NewDNSChecker(endpoint.Check.RecordType, endpoint.Check.Expected, endpoint.DNSResolver), nil
+	case model.CheckTLS:
+		return checker.NewTLSChecker(endpoint.TLSOptions()), nil
+	case model.CheckGRPC:
+		var tls *httpclient.TLSOptions
+		if endpoint.Check.GRPCTLS {
+			opts := endpoint.TLSOptions()
+			tls = &opts
+		}
+		return checker.NewGRPCChecker(endpoint.Check.GRPCService, tls), nil
+	}
+	return nil, errors.New("invalid check type")
+}
+
+// checkRunner runs checks, it is shared by the server and the probes.
+type checkRunner struct {
+	httpClient    *httpclient.Client
+	checkTimeouts CheckTimeouts
+}
+
+// run performs a check of endpoint and evaluates its success criteria, steps holds the step
+// results of scripted checks.
+func (r *checkRunner) run(ctx context.Context, endpoint *model.Endpoint) (*httpclient.Response, []model.StepResult, error) {
+	if endpoint.CheckType == model.CheckScript {
+		return r.runScript(ctx, endpoint)
+	}
+
+	if !endpoint.CheckType.IsHTTP() {
+		res, err := r.runChecker(ctx, endpoint)
+		if err == nil {
+			err = evaluateContent(&endpoint.Criteria, res)
+		}
+		return res, nil, err
+	}
+
+	res, err := r.httpClient.Do(
+		ctx,
+		string(endpoint.HTTPMethod),
+		endpoint.URL,
+		[]byte(endpoint.HTTPRequestBody),
+		endpoint.Headers,
+		r.httpOptions(endpoint),
+	)
+	if err == nil {
+		err = evaluateCriteria(&endpoint.Criteria, res)
+	}
+	return res, nil, err
+}
+
+// runChecker runs a non http check within the endpoint timeout, its result is reported as a
+// response without status code: 0 when the check succeeded and -1 otherwise.
+func (r *checkRunner) runChecker(ctx context.Context, endpoint *model.Endpoint) (*httpclient.Response, error) {
+	response := &httpclient.Response{StatusCode: -1}
+
+	c, err := newChecker(endpoint)
+	if err != nil {
+		return response, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(endpoint.Timeout)*time.Second)
+	defer cancel()
+	result, err := c.Check(ctx, endpoint.URL)
+	response.Body = result.Body
+	response.Timings = result.Timings
+	response.TLS = result.TLS
+	if err != nil {
+		return response, err
+	}
+	response.StatusCode = 0
+	return response, nil
+}
+
+// bind keeps the connection pool of an endpoint checked over http between its checks.
+func (r *checkRunner) bind(endpoint *model.Endpoint) {
+	if endpoint.CheckType != model.CheckScript && !endpoint.CheckType.IsHTTP() {
+		r.httpClient.Release(endpoint.ID)
+		return
+	}
+	r.httpClient.Bind(endpoint.ID, r.httpOptions(endpoint))
+}
+
+// release lets the connection pool of an endpoint no longer checked go.
+func (r *checkRunner) release(id uint) {
+	r.httpClient.Release(id)
+}
+
+// httpOptions returns the options of the http requests of an endpoint check.
+func (r *checkRunner) httpOptions(endpoint *model.Endpoint) httpclient.Options {
+	return httpclient.Options{
+		Timeout:               time.Duration(endpoint.Timeout) * time.Second,
+		ConnectTimeout:        r.checkTimeouts.Connect,
+		TLSHandshakeTimeout:   r.checkTimeouts.TLSHandshake,
+		ResponseHeaderTimeout: r.checkTimeouts.ResponseHeader,
+		FreshConnection:       endpoint.FreshConnection,
+		Proxy:                 endpoint.Proxy,
+		DNSResolver:           endpoint.DNSResolver,
+		NoRedirects:           endpoint.RedirectPolicy == model.RedirectNoFollow,
+		MaxRedirects:          endpoint.MaxRedirects,
+		TLS:                   endpoint.TLSOptions(),
+	}
+}
+
+// logCheck stores the outcome of a check run from the location of the server.
+func (s *endpointService) logCheck(
+	endpoint *model.Endpoint,
+	attempt int,
+	res *httpclient.Response,
+	steps []model.StepResult,
+	err error,
+) *model.CheckLog {
+	checkLog := newCheckLog(endpoint, s.location, attempt, res, steps, err)
+	s.bodyPolicy.apply(checkLog)
+	s.checkLogRepo.Create(checkLog)
+
+	return checkLog
+}
+
+// newCheckLog records the outcome of a check, steps holds the step results of scripted checks.
+func newCheckLog(
+	endpoint *model.Endpoint,
+	location string,
+	attempt int,
+	res *httpclient.Response,
+	steps []model.StepResult,
+	err error,
+) *model.CheckLog {
+	checkLog := &model.CheckLog{
+		TeamID:           endpoint.TeamID,
+		EndpointID:       endpoint.ID,
+		Location:         location,
+		Attempt:          attempt,
+		ResultStatusCode: res.StatusCode,
+		ResultBody:       string(res.Body),
+		Duration:         milliseconds(res.Timings.Total),
+		DNSDuration:      milliseconds(res.Timings.DNS),
+		ConnectDuration:  milliseconds(res.Timings.Connect),
+		TLSDuration:      milliseconds(res.Timings.TLS),
+		TTFB:             milliseconds(res.Timings.TTFB),
+	}
+	if steps != nil {
+		stepResults, _ := json.Marshal(steps)
+		checkLog.StepResults = string(stepResults)
+	}
+	if err != nil {
+		checkLog.Error = err.Error()
+		checkLog.ErrorClass = classifyCheckError(err)
+		var assertionErr *assertionError
+		if errors.As(err, &assertionErr) {
+			checkLog.FailedAssertion = assertionErr.assertion
+		}
+	}
+
+	return checkLog
+}
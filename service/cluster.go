@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/pkg/cluster"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrInvalidInstanceTTL = errors.New("instance ttl must be longer than the heartbeat interval")
+
+type ClusterService interface {
+	InstanceID() string
+	// Enabled reports whether the instance runs in cluster mode, alone otherwise.
+	Enabled() bool
+	// Interval is how often the membership of the cluster is refreshed.
+	Interval() time.Duration
+	// Owns reports whether the endpoint is checked by this instance. In cluster mode every
+	// endpoint is owned by one of the live instances, and by none while this instance cannot
+	// reach the database since the others take over its endpoints.
+	Owns(endpointID uint) bool
+	// Leader reports whether this instance runs the jobs done once for the whole cluster, it is
+	// the live instance with the lowest id.
+	Leader() bool
+	FetchInstances() ([]*model.Instance, error)
+	Shutdown()
+}
+
+type clusterService struct {
+	enabled           bool
+	instance          *model.Instance
+	heartbeatInterval time.Duration
+	instanceTTL       time.Duration
+	cancel            context.CancelFunc
+	instanceRepo      repository.InstanceRepository
+
+	// refreshMu serializes refreshes with leaving the cluster, no heartbeat follows leaving
+	refreshMu sync.Mutex
+	left      bool
+
+	mu          sync.RWMutex
+	members     []string
+	refreshedAt time.Time
+}
+
+// NewClusterService registers the instance and waits for its first view of the cluster, which
+// it then refreshes every heartbeat interval. An instance is dropped from the cluster when it
+// has not sent a heartbeat within instanceTTL. Without enabled the instance owns every endpoint.
+func NewClusterService(
+	enabled bool,
+	instanceID string,
+	heartbeatInterval, instanceTTL time.Duration,
+	wg *sync.WaitGroup,
+	instanceRepo repository.InstanceRepository,
+) (ClusterService, error) {
+	hostname, _ := os.Hostname()
+	if instanceID == "" {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		instanceID = hostname + "-" + hex.EncodeToString(suffix)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	clusterService := &clusterService{
+		enabled: enabled,
+		instance: &model.Instance{
+			ID:        instanceID,
+			Hostname:  hostname,
+			StartedAt: time.Now(),
+		},
+		heartbeatInterval: heartbeatInterval,
+		instanceTTL:       instanceTTL,
+		cancel:            cancel,
+		instanceRepo:      instanceRepo,
+	}
+	if !enabled {
+		return clusterService, nil
+	}
+	if instanceTTL <= heartbeatInterval {
+		cancel()
+		return nil, ErrInvalidInstanceTTL
+	}
+
+	if err := clusterService.refresh(); err != nil {
+		cancel()
+		return nil, err
+	}
+	log.Println("instance", instanceID, "joined the cluster")
+
+	wg.Add(1)
+	go clusterService.heartbeat(ctx, wg)
+
+	return clusterService, nil
+}
+
+func (s *clusterService) InstanceID() string {
+	return s.instance.ID
+}
+
+func (s *clusterService) Enabled() bool {
+	return s.enabled
+}
+
+func (s *clusterService) Interval() time.Duration {
+	return s.heartbeatInterval
+}
+
+func (s *clusterService) Owns(endpointID uint) bool {
+	if !s.enabled {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if time.Since(s.refreshedAt) > s.instanceTTL {
+		return false
+	}
+	owner, ok := cluster.Owner(endpointID, s.members)
+	return ok && owner == s.instance.ID
+}
+
+func (s *clusterService) Leader() bool {
+	if !s.enabled {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if time.Since(s.refreshedAt) > s.instanceTTL {
+		return false
+	}
+	return len(s.members) > 0 && s.members[0] == s.instance.ID
+}
+
+func (s *clusterService) FetchInstances() ([]*model.Instance, error) {
+	if !s.enabled {
+		instance := *s.instance
+		instance.HeartbeatAt = time.Now()
+		return []*model.Instance{&instance}, nil
+	}
+
+	instances, err := s.instanceRepo.FetchLive(s.instanceTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// Shutdown leaves the cluster right away so that the other instances take over the endpoints
+// of this one at their next refresh rather than after the instance ttl.
+func (s *clusterService) Shutdown() {
+	s.cancel()
+	if !s.enabled {
+		return
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	s.left = true
+	s.mu.Lock()
+	s.members = nil
+	s.mu.Unlock()
+	if err := s.instanceRepo.Delete(s.instance.ID); err != nil {
+		log.Println("failed to leave the cluster, err:", err.Error())
+	}
+}
+
+func (s *clusterService) heartbeat(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refresh(); err != nil {
+				log.Println("failed to refresh the cluster membership, err:", err.Error())
+			}
+		}
+	}
+}
+
+// refresh sends a heartbeat and reloads the live instances.
+func (s *clusterService) refresh() error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	if s.left {
+		return nil
+	}
+
+	if err := s.instanceRepo.Heartbeat(s.instance); err != nil {
+		return err
+	}
+	instances, err := s.instanceRepo.FetchLive(s.instanceTTL)
+	if err != nil {
+		return err
+	}
+	if err := s.instanceRepo.DeleteExpired(s.instanceTTL); err != nil {
+		log.Println("failed to delete expired instances, err:", err.Error())
+	}
+
+	members := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		members = append(members, instance.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Equal(s.members, members) {
+		log.Println("cluster members:", members)
+	}
+	s.members = members
+	s.refreshedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testHeartbeat   = 20 * time.Millisecond
+	testInstanceTTL = 100 * time.Millisecond
+	testEndpoints   = 200
+)
+
+// memoryInstanceRepository stands in for the instances table shared by the instances of a
+// cluster, its clock plays the part of the database clock.
+type memoryInstanceRepository struct {
+	mu          sync.Mutex
+	instances   map[string]*model.Instance
+	unreachable map[string]bool
+}
+
+func newMemoryInstanceRepository() *memoryInstanceRepository {
+	return &memoryInstanceRepository{
+		instances:   make(map[string]*model.Instance),
+		unreachable: make(map[string]bool),
+	}
+}
+
+// cut makes the database unreachable from an instance, as if it crashed or was partitioned.
+func (r *memoryInstanceRepository) cut(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unreachable[id] = true
+}
+
+func (r *memoryInstanceRepository) Heartbeat(instance *model.Instance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unreachable[instance.ID] {
+		return repository.ErrUpdate
+	}
+	stored := *instance
+	stored.HeartbeatAt = time.Now()
+	r.instances[instance.ID] = &stored
+	return nil
+}
+
+func (r *memoryInstanceRepository) FetchLive(ttl time.Duration) ([]*model.Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var live []*model.Instance
+	for _, instance := range r.instances {
+		if time.Since(instance.HeartbeatAt) < ttl {
+			copied := *instance
+			live = append(live, &copied)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].ID < live[j].ID })
+	return live, nil
+}
+
+func (r *memoryInstanceRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, id)
+	return nil
+}
+
+func (r *memoryInstanceRepository) DeleteExpired(ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, instance := range r.instances {
+		if time.Since(instance.HeartbeatAt) >= ttl {
+			delete(r.instances, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryInstanceRepository) Lock(name string) (func(), error) {
+	return func() {}, nil
+}
+
+func joinCluster(t *testing.T, repo repository.InstanceRepository, wg *sync.WaitGroup, id string) ClusterService {
+	t.Helper()
+	instance, err := NewClusterService(true, id, testHeartbeat, testInstanceTTL, wg, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instance
+}
+
+// assertOwnership checks that every endpoint is owned by exactly one of the instances, and
+// that only the instances listed in owners do.
+func assertOwnership(t *testing.T, instances []ClusterService, owners []string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * testInstanceTTL)
+	for {
+		err := ownership(instances, owners)
+		if err == "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(testHeartbeat)
+	}
+}
+
+func ownership(instances []ClusterService, owners []string) string {
+	leaders := 0
+	for _, instance := range instances {
+		if instance.Leader() {
+			leaders++
+		}
+	}
+	if leaders != 1 {
+		return "the cluster does not have exactly one leader"
+	}
+
+	seen := make(map[string]bool)
+	for endpointID := uint(1); endpointID <= testEndpoints; endpointID++ {
+		owned := 0
+		for _, instance := range instances {
+			if instance.Owns(endpointID) {
+				owned++
+				seen[instance.InstanceID()] = true
+			}
+		}
+		if owned != 1 {
+			return "an endpoint is not owned by exactly one instance"
+		}
+	}
+	for id := range seen {
+		if !slices.Contains(owners, id) {
+			return "an instance outside the owners owns endpoints"
+		}
+	}
+	if len(seen) != len(owners) {
+		return "an owner owns no endpoint"
+	}
+	return ""
+}
+
+func TestClusterRebalancesOwnership(t *testing.T) {
+	repo := newMemoryInstanceRepository()
+	wg := &sync.WaitGroup{}
+	a := joinCluster(t, repo, wg, "a")
+	b := joinCluster(t, repo, wg, "b")
+	c := joinCluster(t, repo, wg, "c")
+	defer func() {
+		a.Shutdown()
+		b.Shutdown()
+		c.Shutdown()
+		wg.Wait()
+	}()
+
+	assertOwnership(t, []ClusterService{a, b, c}, []string{"a", "b", "c"})
+	if !a.Leader() {
+		t.Fatal("the instance with the lowest id does not lead")
+	}
+
+	// an instance shutting down leaves right away, the leader is the next lowest id
+	a.Shutdown()
+	if a.Owns(1) || a.Leader() {
+		t.Fatal("an instance that left still owns endpoints")
+	}
+	assertOwnership(t, []ClusterService{a, b, c}, []string{"b", "c"})
+	if !b.Leader() {
+		t.Fatal("the leadership did not move to the next instance")
+	}
+
+	// an instance that cannot reach the database stops owning endpoints once its view is
+	// older than the ttl, and the others take them over once its heartbeat expires
+	repo.cut("c")
+	assertOwnership(t, []ClusterService{a, b, c}, []string{"b"})
+
+	d := joinCluster(t, repo, wg, "d")
+	defer d.Shutdown()
+	assertOwnership(t, []ClusterService{a, b, c, d}, []string{"b", "d"})
+}
+
+func TestClusterDisabledOwnsEverything(t *testing.T) {
+	instance, err := NewClusterService(false, "solo", testHeartbeat, testInstanceTTL, &sync.WaitGroup{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !instance.Owns(1) || !instance.Leader() {
+		t.Fatal("an instance outside cluster mode must own every endpoint and lead")
+	}
+	instances, err := instance.FetchInstances()
+	if err != nil || len(instances) != 1 || instances[0].ID != "solo" {
+		t.Fatalf("FetchInstances() = %v, %v, want the instance alone", instances, err)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/pkg/metrics"
+	"healthcheck/pkg/notifier"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	deliveryPollInterval = time.Second
+	deliveryBatchSize    = 10
+	// deliveryLease is how long a claimed batch is left to this instance, the deliveries of an
+	// instance that dies meanwhile are attempted again by another one once it ends
+	deliveryLease = deliveryBatchSize*notificationTimeout + time.Minute
+)
+
+var ErrDeliveryNotDead = errors.New("only dead deliveries can be redelivered")
+
+type DeliveryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts.
+func (p DeliveryPolicy) backoff(attempts int) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+func (s *notificationService) FetchDeliveries(scope repository.Scope, filter repository.DeliveryFilter) ([]*model.NotificationDelivery, error) {
+	deliveries, err := s.deliveryRepo.FetchAll(scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (s *notificationService) GetDelivery(scope repository.Scope, id uint) (*model.NotificationDelivery, error) {
+	delivery, err := s.deliveryRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver queues a dead delivery again with a fresh attempt budget, deliveries that went out
+// or are still being attempted are left alone.
+func (s *notificationService) Redeliver(scope repository.Scope, id uint) error {
+	delivery, err := s.deliveryRepo.FetchByID(scope, id)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != model.DeliveryDead {
+		return ErrDeliveryNotDead
+	}
+
+	delivery.Status = model.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+	if err := s.deliveryRepo.Update(delivery); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *notificationService) Shutdown() {
+	s.cancel()
+}
+
+func (s *notificationService) enqueue(scope repository.Scope, channelID uint, notification notifier.Notification) {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		log.Println("failed to marshal notification, err:", err.Error())
+		return
+	}
+
+	delivery := &model.NotificationDelivery{
+		TeamID:        scope.TeamID,
+		ChannelID:     channelID,
+		EndpointID:    notification.EndpointID,
+		Payload:       string(payload),
+		Status:        model.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.deliveryRepo.Create(delivery); err != nil {
+		log.Println("failed to queue notification for channel ", channelID, ", err:", err.Error())
+	}
+}
+
+func (s *notificationService) dispatch(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("notification dispatcher is shutting down")
+			return
+		case <-time.After(deliveryPollInterval):
+			s.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue delivers the due deliveries batch by batch until none is left.
+func (s *notificationService) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		deliveries, err := s.deliveryRepo.ClaimDue(now, now.Add(deliveryLease), deliveryBatchSize)
+		if err != nil {
+			return
+		}
+		for _, delivery := range deliveries {
+			s.deliver(ctx, delivery)
+		}
+		if len(deliveries) < deliveryBatchSize {
+			return
+		}
+	}
+}
+
+func (s *notificationService) deliver(ctx context.Context, delivery *model.NotificationDelivery) {
+	delivery.Attempts++
+	start := time.Now()
+	err := s.send(ctx, delivery)
+	attempt := &model.DeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		Duration:   milliseconds(time.Since(start)),
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		s.metrics.ObserveDelivery(metrics.DeliveryDelivered)
+	} else {
+		log.Println("failed to deliver notification ", delivery.ID, ", attempt ", delivery.Attempts, ", err:", err.Error())
+		attempt.Error = err.Error()
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= s.policy.MaxAttempts {
+			delivery.Status = model.DeliveryDead
+			s.metrics.ObserveDelivery(metrics.DeliveryDead)
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(s.policy.backoff(delivery.Attempts))
+			s.metrics.ObserveDelivery(metrics.DeliveryFailed)
+		}
+	}
+
+	s.deliveryRepo.CreateAttempt(attempt)
+	s.deliveryRepo.Update(delivery)
+}
+
+func (s *notificationService) send(ctx context.Context, delivery *model.NotificationDelivery) error {
+	notification := notifier.Notification{}
+	if err := json.Unmarshal([]byte(delivery.Payload), &notification); err != nil {
+		return err
+	}
+
+	var n notifier.Notifier
+	if delivery.ChannelID == 0 {
+		n = notifier.NewWebhookNotifier(s.defaultWebhookURL, s.defaultWebhookSecret)
+	} else {
+		channel, err := s.channelRepo.FetchByID(repository.AllTeams, delivery.ChannelID)
+		if err != nil {
+			return err
+		}
+		if err := prepareChannel(channel); err != nil {
+			return err
+		}
+		n = newNotifier(channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+	return n.Notify(ctx, notification)
+}
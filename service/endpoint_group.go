@@ -0,0 +1,78 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+)
+
+type EndpointGroupService interface {
+	// CreateGroup creates the group in the team of the scope.
+	CreateGroup(scope repository.Scope, name, description string) (*model.EndpointGroup, error)
+	FetchAllGroups(scope repository.Scope) ([]*model.EndpointGroup, error)
+	GetGroup(scope repository.Scope, id uint) (*model.EndpointGroup, error)
+	UpdateGroup(scope repository.Scope, id uint, name, description string) error
+	DeleteGroup(scope repository.Scope, id uint) error
+}
+
+type endpointGroupService struct {
+	groupRepo repository.EndpointGroupRepository
+}
+
+func NewEndpointGroupService(groupRepo repository.EndpointGroupRepository) EndpointGroupService {
+	return &endpointGroupService{groupRepo}
+}
+
+func (s *endpointGroupService) CreateGroup(scope repository.Scope, name, description string) (*model.EndpointGroup, error) {
+	group := &model.EndpointGroup{TeamID: scope.TeamID, Name: name, Description: description}
+	if err := s.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *endpointGroupService) FetchAllGroups(scope repository.Scope) ([]*model.EndpointGroup, error) {
+	groups, err := s.groupRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (s *endpointGroupService) GetGroup(scope repository.Scope, id uint) (*model.EndpointGroup, error) {
+	group, err := s.groupRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *endpointGroupService) UpdateGroup(scope repository.Scope, id uint, name, description string) error {
+	group, err := s.groupRepo.FetchByID(scope, id)
+	if err != nil {
+		return err
+	}
+
+	group.Name = name
+	group.Description = description
+	if err := s.groupRepo.Update(group); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroup removes the group, its endpoints are kept without a group.
+func (s *endpointGroupService) DeleteGroup(scope repository.Scope, id uint) error {
+	if _, err := s.groupRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return nil
+}
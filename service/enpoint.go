@@ -1,129 +1,459 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"healthcheck/internal/model"
 	"healthcheck/internal/repository"
 	httpclient "healthcheck/pkg/http_client"
+	"healthcheck/pkg/metrics"
+	"healthcheck/pkg/notifier"
 	"log"
-	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
+var (
+	ErrEndpointNameTaken = errors.New("endpoint name is already taken")
+	ErrUnknownGroup      = errors.New("group does not exist")
+	ErrActiveAgent       = errors.New("agent is active")
+	ErrInActiveAgent     = errors.New("agent is not active")
+)
+
+type EndpointParams struct {
+	Name               string
+	GroupID            *uint
+	CheckType          string
+	URL                string
+	HTTPMethod         string
+	HTTPRequestHeaders string // json encoded list of key/value pairs
+	HTTPRequestBody    string
+	SuccessCriteria    string // json encoded model.SuccessCriteria
+	CheckConfig        string // json encoded model.CheckConfig
+	Interval           int
+	Timeout            int // 0 uses model.DefaultTimeout
+	Retries            int
+	FreshConnection    bool
+	Proxy              string
+	DNSResolver        string
+	RedirectPolicy     string
+	MaxRedirects       int
+	CABundle           string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	Public             bool
+	Locations          string // json encoded list of location names
+	Quorum             int
+}
+
+func NewEndpointParams(endpoint *model.Endpoint) EndpointParams {
+	return EndpointParams{
+		Name:               endpoint.Name,
+		GroupID:            endpoint.GroupID,
+		CheckType:          string(endpoint.CheckType),
+		URL:                endpoint.URL,
+		HTTPMethod:         string(endpoint.HTTPMethod),
+		HTTPRequestHeaders: endpoint.HTTPRequestHeaders,
+		HTTPRequestBody:    endpoint.HTTPRequestBody,
+		SuccessCriteria:    endpoint.SuccessCriteria,
+		CheckConfig:        endpoint.CheckConfig,
+		Interval:           endpoint.Interval,
+		Timeout:            endpoint.Timeout,
+		Retries:            endpoint.Retries,
+		FreshConnection:    endpoint.FreshConnection,
+		Proxy:              endpoint.Proxy,
+		DNSResolver:        endpoint.DNSResolver,
+		RedirectPolicy:     string(endpoint.RedirectPolicy),
+		MaxRedirects:       endpoint.MaxRedirects,
+		CABundle:           endpoint.CABundle,
+		ClientCert:         endpoint.ClientCert,
+		ClientKey:          endpoint.ClientKey,
+		InsecureSkipVerify: endpoint.InsecureSkipVerify,
+		Public:             endpoint.Public,
+		Locations:          endpoint.Locations,
+		Quorum:             endpoint.Quorum,
+	}
+}
+
+func (p EndpointParams) apply(endpoint *model.Endpoint) error {
+	if p.HTTPMethod == "" {
+		p.HTTPMethod = string(model.MethodGet)
+	}
+	if err := model.HTTPMethod(p.HTTPMethod).Validate(); err != nil {
+		return err
+	}
+
+	endpoint.Name = p.Name
+	endpoint.GroupID = p.GroupID
+	endpoint.CheckType = model.CheckType(p.CheckType)
+	endpoint.URL = p.URL
+	endpoint.HTTPMethod = model.HTTPMethod(p.HTTPMethod)
+	endpoint.HTTPRequestHeaders = p.HTTPRequestHeaders
+	endpoint.HTTPRequestBody = p.HTTPRequestBody
+	endpoint.SuccessCriteria = p.SuccessCriteria
+	endpoint.CheckConfig = p.CheckConfig
+	endpoint.Interval = p.Interval
+	endpoint.Timeout = p.Timeout
+	endpoint.Retries = p.Retries
+	endpoint.FreshConnection = p.FreshConnection
+	endpoint.Proxy = p.Proxy
+	endpoint.DNSResolver = p.DNSResolver
+	endpoint.RedirectPolicy = model.RedirectPolicy(p.RedirectPolicy)
+	endpoint.MaxRedirects = p.MaxRedirects
+	endpoint.CABundle = p.CABundle
+	endpoint.ClientCert = p.ClientCert
+	endpoint.ClientKey = p.ClientKey
+	endpoint.InsecureSkipVerify = p.InsecureSkipVerify
+	endpoint.Public = p.Public
+	endpoint.Locations = p.Locations
+	endpoint.Quorum = p.Quorum
+	if err := endpoint.ValidateSchedule(); err != nil {
+		return err
+	}
+	if err := endpoint.ValidateConnection(); err != nil {
+		return err
+	}
+	if err := endpoint.ValidateVisibility(); err != nil {
+		return err
+	}
+
+	return prepareEndpoint(endpoint)
+}
+
+// CheckTimeouts bounds the phases of every check, each is capped by the endpoint timeout
+// and a zero value leaves the phase bounded by the endpoint timeout only.
+type CheckTimeouts struct {
+	Connect        time.Duration
+	TLSHandshake   time.Duration
+	ResponseHeader time.Duration
+}
+
 type EndpointService interface {
-	CreateEndpoint(url, httpMethod, httpRequestHeaders, httpRequestBody string, interval, retries int) error
-	FetchAllEndpoints() ([]*model.Endpoint, error)
-	UpdateEndpointActivationStatus(id uint, isActive bool) error
-	DeleteEndpoint(id uint) error
+	// CreateEndpoint creates the endpoint in the team of the scope, within its quotas.
+	CreateEndpoint(scope repository.Scope, params EndpointParams) (*model.Endpoint, error)
+	GetEndpoint(scope repository.Scope, id uint) (*model.Endpoint, error)
+	UpdateEndpoint(scope repository.Scope, id uint, params EndpointParams) error
+	FetchAllEndpoints(scope repository.Scope) ([]*model.Endpoint, error)
+	FetchEndpointCheckLogs(scope repository.Scope, id uint, filter repository.CheckLogFilter) ([]*model.CheckLog, error)
+	UpdateEndpointActivationStatus(scope repository.Scope, id uint, isActive bool) error
+	DeleteEndpoint(scope repository.Scope, id uint) error
+	// FetchEndpointLocations returns the status of a distributed endpoint from each of its
+	// locations that checked it.
+	FetchEndpointLocations(scope repository.Scope, id uint) ([]*model.LocationStatus, error)
+	// FetchAllAgents and GetAgent only see the agents running on this instance.
+	FetchAllAgents(scope repository.Scope) []model.HealthCheckAgentState
+	GetAgent(scope repository.Scope, id uint) (model.HealthCheckAgentState, error)
 	Shutdown()
 }
 
 type endpointService struct {
-	webhookURL           string
-	wg                   *sync.WaitGroup
-	endpointRepo         repository.EndpointRepository
-	checkLogRepo         repository.CheckLogRepository
-	healthCheckAgentRepo repository.HealthCheckAgentRepository
+	runner                 *checkRunner
+	bodyPolicy             BodyPolicy
+	location               string
+	certificateWarningDays int
+	notificationService    NotificationService
+	clusterService         ClusterService
+	metrics                *metrics.Metrics
+	cancel                 context.CancelFunc
+	endpointRepo           repository.EndpointRepository
+	checkLogRepo           repository.CheckLogRepository
+	incidentRepo           repository.IncidentRepository
+	certificateRepo        repository.TLSCertificateRepository
+	healthCheckAgentRepo   repository.HealthCheckAgentRepository
+	teamRepo               repository.TeamRepository
+	groupRepo              repository.EndpointGroupRepository
+	locationStatusRepo     repository.LocationStatusRepository
+
+	// syncMu serializes syncing agents with the database, so that a sync started before a
+	// change cannot undo the sync of the change
+	syncMu sync.Mutex
 }
 
+// NewEndpointService starts the agents of the endpoints owned by this instance. In cluster mode
+// they are synced again at every membership refresh, which rebalances the endpoints between the
+// instances and picks up the changes made through the other instances.
 func NewEndpointService(
-	webhookURL string,
+	httpClient *httpclient.Client,
+	checkTimeouts CheckTimeouts,
+	bodyPolicy BodyPolicy,
+	location string,
+	certificateWarningDays int,
+	notificationService NotificationService,
+	clusterService ClusterService,
 	wg *sync.WaitGroup,
+	metrics *metrics.Metrics,
 	checkLogRepo repository.CheckLogRepository,
 	endpointRepo repository.EndpointRepository,
+	incidentRepo repository.IncidentRepository,
+	certificateRepo repository.TLSCertificateRepository,
 	healthCheckAgentRepo repository.HealthCheckAgentRepository,
+	teamRepo repository.TeamRepository,
+	groupRepo repository.EndpointGroupRepository,
+	locationStatusRepo repository.LocationStatusRepository,
 ) (EndpointService, error) {
-	endpointService := &endpointService{webhookURL, wg, endpointRepo, checkLogRepo, healthCheckAgentRepo}
+	ctx, cancel := context.WithCancel(context.Background())
+	endpointService := &endpointService{
+		runner:                 &checkRunner{httpClient, checkTimeouts},
+		bodyPolicy:             bodyPolicy,
+		location:               location,
+		certificateWarningDays: certificateWarningDays,
+		notificationService:    notificationService,
+		clusterService:         clusterService,
+		metrics:                metrics,
+		cancel:                 cancel,
+		endpointRepo:           endpointRepo,
+		checkLogRepo:           checkLogRepo,
+		incidentRepo:           incidentRepo,
+		certificateRepo:        certificateRepo,
+		healthCheckAgentRepo:   healthCheckAgentRepo,
+		teamRepo:               teamRepo,
+		groupRepo:              groupRepo,
+		locationStatusRepo:     locationStatusRepo,
+	}
+	metrics.RegisterAgents(endpointService.countAgents)
 	if err := endpointService.bootstrap(); err != nil {
+		cancel()
 		log.Println("failed to bootstrap endpoint service, err:", err.Error())
 		return nil, err
 	}
+	if clusterService.Enabled() {
+		wg.Add(1)
+		go endpointService.rebalance(ctx, wg)
+	}
 	return endpointService, nil
 }
 
-func (s *endpointService) CreateEndpoint(url, httpMethod, httpRequestHeaders, httpRequestBody string, interval, retries int) error {
-	err := model.HTTPMethod(httpMethod).Validate()
+func (s *endpointService) CreateEndpoint(scope repository.Scope, params EndpointParams) (*model.Endpoint, error) {
+	model := &model.Endpoint{TeamID: scope.TeamID}
+	if err := params.apply(model); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameAvailable(model); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkGroup(model); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkQuotas(model, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.endpointRepo.Create(model); err != nil {
+		return nil, err
+	}
+	s.applyAgent(model)
+
+	return model, nil
+}
+
+func (s *endpointService) GetEndpoint(scope repository.Scope, id uint) (*model.Endpoint, error) {
+	model, err := s.endpointRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	setCertificateExpiry(model, time.Now())
+
+	return model, nil
+}
+
+func (s *endpointService) UpdateEndpoint(scope repository.Scope, id uint, params EndpointParams) error {
+	model, err := s.endpointRepo.FetchByID(scope, id)
 	if err != nil {
 		return err
 	}
 
-	model := &model.Endpoint{
-		URL:                url,
-		HTTPMethod:         model.HTTPMethod(httpMethod),
-		HTTPRequestHeaders: httpRequestHeaders,
-		HTTPRequestBody:    httpRequestBody,
-		Interval:           interval,
-		Retries:            retries,
+	if err := params.apply(model); err != nil {
+		return err
 	}
 
-	if err := s.endpointRepo.Create(model); err != nil {
+	if err := s.checkNameAvailable(model); err != nil {
 		return err
 	}
 
-	Headers := []struct {
-		Key   string `json:"key"`
-		Value string `json:"value"`
-	}{}
-	if err := json.Unmarshal([]byte(httpRequestHeaders), &Headers); err != nil {
+	if err := s.checkGroup(model); err != nil {
+		return err
+	}
+
+	if err := s.checkQuotas(model, false); err != nil {
+		return err
+	}
+
+	if err := s.endpointRepo.Update(model); err != nil {
+		return err
+	}
+	s.applyAgent(model)
+
+	return nil
+}
+
+// checkNameAvailable makes sure no other endpoint of the team has the name of endpoint.
+func (s *endpointService) checkNameAvailable(endpoint *model.Endpoint) error {
+	if endpoint.Name == "" {
+		return nil
+	}
+	other, err := s.endpointRepo.FetchByName(repository.TeamScope(endpoint.TeamID), endpoint.Name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
-	model.Headers = make(map[string]string)
-	for i := range Headers {
-		model.Headers[Headers[i].Key] = Headers[i].Value
+	if other.ID != endpoint.ID {
+		return ErrEndpointNameTaken
 	}
+	return nil
+}
+
+// checkGroup makes sure the group of endpoint, if any, belongs to the team of the endpoint.
+func (s *endpointService) checkGroup(endpoint *model.Endpoint) error {
+	if endpoint.GroupID == nil {
+		return nil
+	}
+	_, err := s.groupRepo.FetchByID(repository.TeamScope(endpoint.TeamID), *endpoint.GroupID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUnknownGroup
+	}
+	return err
+}
 
-	if err := s.healthCheckAgentRepo.Create(model, s.agentFactory()); err != nil {
+// checkQuotas enforces the quotas of the team of endpoint, the endpoint count only counts when
+// the endpoint is created.
+func (s *endpointService) checkQuotas(endpoint *model.Endpoint, creating bool) error {
+	team, err := s.teamRepo.FetchByID(endpoint.TeamID)
+	if err != nil {
 		return err
 	}
+	if err := team.ValidateInterval(endpoint.Interval); err != nil {
+		return err
+	}
+	if !creating || team.MaxEndpoints == 0 {
+		return nil
+	}
 
+	count, err := s.endpointRepo.Count(repository.TeamScope(team.ID))
+	if err != nil {
+		return err
+	}
+	if !team.AllowsEndpoints(count + 1) {
+		return model.ErrEndpointQuota
+	}
 	return nil
 }
 
-func (s *endpointService) FetchAllEndpoints() ([]*model.Endpoint, error) {
-	models, err := s.endpointRepo.FetchAll()
+func (s *endpointService) FetchAllEndpoints(scope repository.Scope) ([]*model.Endpoint, error) {
+	models, err := s.endpointRepo.FetchAll(scope)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	for _, model := range models {
+		setCertificateExpiry(model, now)
+	}
 
 	return models, nil
 }
 
-func (s *endpointService) UpdateEndpointActivationStatus(id uint, isActive bool) error {
-	if isActive {
-		if err := s.healthCheckAgentRepo.Start(id, s.wg); err != nil {
-			return err
+func (s *endpointService) FetchEndpointCheckLogs(scope repository.Scope, id uint, filter repository.CheckLogFilter) ([]*model.CheckLog, error) {
+	checkLogs, err := s.checkLogRepo.FetchByEndpointID(scope, id, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkLogs, nil
+}
+
+func (s *endpointService) FetchEndpointLocations(scope repository.Scope, id uint) ([]*model.LocationStatus, error) {
+	model, err := s.endpointRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareEndpoint(model); err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.locationStatusRepo.FetchByEndpointID(id)
+	if err != nil {
+		return nil, err
+	}
+	locations := model.CheckedFrom(s.location)
+	current := statuses[:0]
+	for _, status := range statuses {
+		if slices.Contains(locations, status.Location) {
+			current = append(current, status)
 		}
-	} else {
-		if err := s.healthCheckAgentRepo.Stop(id); err != nil {
-			return err
+	}
+
+	return current, nil
+}
+
+func (s *endpointService) UpdateEndpointActivationStatus(scope repository.Scope, id uint, isActive bool) error {
+	model, err := s.endpointRepo.FetchByID(scope, id)
+	if err != nil {
+		return err
+	}
+	if model.ActiveCheck == isActive {
+		if isActive {
+			return ErrActiveAgent
 		}
+		return ErrInActiveAgent
+	}
+	if err := prepareEndpoint(model); err != nil {
+		return err
 	}
 
 	if err := s.endpointRepo.UpdateCheckActivation(id, isActive); err != nil {
 		return err
 	}
+	model.ActiveCheck = isActive
+	s.applyAgent(model)
 
 	return nil
 }
 
-func (s *endpointService) DeleteEndpoint(id uint) error {
-	if err := s.healthCheckAgentRepo.Delete(id); err != nil {
+func (s *endpointService) DeleteEndpoint(scope repository.Scope, id uint) error {
+	model, err := s.endpointRepo.FetchByID(scope, id)
+	if err != nil {
 		return err
 	}
+	if model.ActiveCheck {
+		return ErrActiveAgent
+	}
 
 	if err := s.endpointRepo.Delete(id); err != nil {
 		return err
 	}
+	if err := s.locationStatusRepo.DeleteByEndpointID(id); err != nil {
+		log.Println("failed to delete location statuses of endpoint ", id, ", err:", err.Error())
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if s.healthCheckAgentRepo.Delete(id) {
+		s.releaseAgent(id)
+	}
 
 	return nil
 }
 
+func (s *endpointService) FetchAllAgents(scope repository.Scope) []model.HealthCheckAgentState {
+	return s.healthCheckAgentRepo.List(scope)
+}
+
+func (s *endpointService) GetAgent(scope repository.Scope, id uint) (model.HealthCheckAgentState, error) {
+	return s.healthCheckAgentRepo.Get(scope, id)
+}
+
 func (s *endpointService) Shutdown() {
+	s.cancel()
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
 	s.healthCheckAgentRepo.StopAll()
 
 	// endpoints, err := s.endpointRepo.FetchAll()
@@ -139,117 +469,405 @@ func (s *endpointService) Shutdown() {
 	// }
 }
 
+func (s *endpointService) countAgents() (active, inactive int) {
+	for _, agent := range s.healthCheckAgentRepo.List(repository.AllTeams) {
+		if agent.IsActive {
+			active++
+		} else {
+			inactive++
+		}
+	}
+	return active, inactive
+}
+
+// observeCheck exports a finished check and the state of the endpoint after it.
+func (s *endpointService) observeCheck(agent *model.HealthCheckAgent, endpoint *model.Endpoint, checkLog *model.CheckLog) {
+	s.metrics.ObserveCheck(metrics.CheckResult{
+		EndpointID:          endpoint.ID,
+		Name:                endpoint.Name,
+		URL:                 endpoint.URL,
+		CheckType:           string(endpoint.CheckType),
+		Up:                  agent.LastStatus(),
+		Duration:            time.Duration(checkLog.Duration * float64(time.Millisecond)),
+		ConsecutiveFailures: agent.ConsecutiveFailures(),
+		ErrorClass:          string(checkLog.ErrorClass),
+	})
+}
+
 func (s *endpointService) bootstrap() error {
-	models, err := s.FetchAllEndpoints()
-	if err != nil {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if err := s.syncAgents(); err != nil {
 		return err
 	}
 
-	for _, model := range models {
-		if model.ActiveCheck {
-			if err := s.healthCheckAgentRepo.Create(model, s.agentFactory()); err != nil {
-				log.Println("failed to create health check agent for endpoint ", model.ID, ", err:", err.Error())
-				continue
+	log.Println("all health check agents started")
+	return nil
+}
+
+func (s *endpointService) rebalance(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(s.clusterService.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncMu.Lock()
+			if ctx.Err() == nil {
+				if err := s.syncAgents(); err != nil {
+					log.Println("failed to sync health check agents, err:", err.Error())
+				}
 			}
+			s.syncMu.Unlock()
+		}
+	}
+}
+
+// syncAgents runs the agents of the endpoints owned by this instance with their latest
+// configuration and removes the others. The agents of endpoints no longer owned are removed
+// even when the endpoints cannot be fetched, another instance checks them by now.
+// The caller must hold syncMu.
+func (s *endpointService) syncAgents() error {
+	models, err := s.endpointRepo.FetchAll(repository.AllTeams)
+	exists := make(map[uint]bool, len(models))
+	for _, model := range models {
+		exists[model.ID] = true
+		if err := prepareEndpoint(model); err != nil {
+			log.Println("failed to prepare endpoint ", model.ID, ", err:", err.Error())
+			continue
+		}
+		s.syncAgent(model)
+	}
 
-			if err := s.healthCheckAgentRepo.Start(model.ID, s.wg); err != nil {
-				log.Println("failed to start health check agent for endpoint ", model.ID, ", err:", err.Error())
+	for _, agent := range s.healthCheckAgentRepo.List(repository.AllTeams) {
+		if (err == nil && !exists[agent.ID]) || !s.clusterService.Owns(agent.ID) {
+			if s.healthCheckAgentRepo.Delete(agent.ID) {
+				s.releaseAgent(agent.ID)
 			}
 		}
 	}
+	return err
+}
 
-	log.Println("all health check agents started")
-	return nil
+// applyAgent syncs the agent of an endpoint changed through this instance.
+func (s *endpointService) applyAgent(endpoint *model.Endpoint) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.syncAgent(endpoint)
+}
+
+// syncAgent runs the agent of endpoint when this instance owns it and removes it otherwise.
+// The caller must hold syncMu.
+func (s *endpointService) syncAgent(endpoint *model.Endpoint) {
+	if !s.clusterService.Owns(endpoint.ID) {
+		if s.healthCheckAgentRepo.Delete(endpoint.ID) {
+			s.releaseAgent(endpoint.ID)
+		}
+		return
+	}
+
+	wasActive := s.healthCheckAgentRepo.Sync(endpoint, s.agentFactory())
+	if endpoint.ActiveCheck {
+		s.runner.bind(endpoint)
+	} else if wasActive {
+		s.releaseAgent(endpoint.ID)
+	}
+}
+
+// releaseAgent drops the metrics and the connection pool of an agent stopped or removed.
+func (s *endpointService) releaseAgent(id uint) {
+	s.metrics.RemoveEndpoint(id)
+	s.runner.release(id)
 }
 
 func (s *endpointService) agentFactory() model.HealthCheckAgentFunctionSignature {
-	healthCheck := func(endpoint *model.Endpoint) error {
-		var err error
-		var body []byte
-		var respStatusCode int
-
-		body, respStatusCode, err = httpclient.Do(
-			context.Background(),
-			string(endpoint.HTTPMethod),
-			endpoint.URL,
-			[]byte(endpoint.HTTPRequestBody),
-			time.Duration(endpoint.Interval)*time.Second,
-			endpoint.Headers,
-		)
-		s.checkLogRepo.Create(endpoint.ID, respStatusCode, string(body))
-		if err != nil {
-			return err
+	healthCheck := func(ctx context.Context, agent *model.HealthCheckAgent, endpoint *model.Endpoint, attempt int) (*model.CheckLog, error) {
+		res, steps, err := s.runner.run(ctx, endpoint)
+		if endpoint.CheckType != model.CheckScript && res.TLS != nil && ctx.Err() == nil {
+			s.recordCertificate(agent, endpoint, res.TLS)
 		}
+		return s.logCheck(endpoint, attempt, res, steps, err), err
+	}
 
-		if respStatusCode != http.StatusOK {
-			return errors.New("unhealthy")
+	// updateStatus records a status transition, checks holds the checks that caused it, the latest last
+	updateStatus := func(agent *model.HealthCheckAgent, endpoint *model.Endpoint, status bool, checks []*model.CheckLog) {
+		lastCheck := checks[len(checks)-1]
+		notification := notifier.Notification{
+			EndpointID:     endpoint.ID,
+			URL:            endpoint.URL,
+			Method:         string(endpoint.HTTPMethod),
+			PreviousStatus: agent.LastStatus(),
+			Status:         status,
+			ChangedAt:      time.Now(),
+			LastStatusCode: lastCheck.ResultStatusCode,
+			Latency:        lastCheck.Duration,
+			FailureReason:  lastCheck.Error,
 		}
-		return nil
+		agent.SetLastStatus(status)
+
+		if err := s.endpointRepo.UpdateLastStatus(endpoint.ID, status); err != nil {
+			log.Println("failed to update last status for endpoint ", endpoint.ID, ", err:", err.Error())
+		}
+
+		if status {
+			notification.IncidentID = s.closeIncident(endpoint)
+		} else {
+			notification.IncidentID = s.openIncident(endpoint, checks)
+		}
+
+		s.notificationService.Notify(notification)
 	}
 
-	webhook := func(endpointID uint, status bool) {
-		payload := struct {
-			Status bool `json:"status"`
-		}{
-			Status: status,
+	// distributedRun performs the check of the server when it is one of the locations of the
+	// endpoint, and then decides the status of the endpoint from the statuses of all its locations
+	distributedRun := func(ctx context.Context, agent *model.HealthCheckAgent, endpoint *model.Endpoint) {
+		if slices.Contains(endpoint.CheckedFrom(s.location), s.location) {
+			checkLog, err := healthCheck(ctx, agent, endpoint, agent.Tries+1)
+			if ctx.Err() != nil {
+				return
+			}
+			defer s.observeCheck(agent, endpoint, checkLog)
+			if err := s.locationStatusRepo.Record(checkLog); err != nil {
+				log.Println("failed to record location status for endpoint ", endpoint.ID, ", err:", err.Error())
+			}
+			if err != nil {
+				agent.Tries++
+				if agent.Tries >= endpoint.Retries {
+					agent.Tries = 0
+				}
+				agent.RecordRun(time.Now(), agent.ConsecutiveFailures()+1)
+				log.Println(endpoint.URL, "health check failed from", s.location, ", err:", err.Error())
+			} else {
+				agent.Tries = 0
+				agent.RecordRun(time.Now(), 0)
+			}
 		}
 
-		jsonPayload, err := json.Marshal(payload)
+		statuses, err := s.locationStatusRepo.FetchByEndpointID(endpoint.ID)
 		if err != nil {
-			log.Println("failed to marshal json payload, err:", err.Error())
 			return
 		}
+		status, deciding, ok := quorumStatus(endpoint, s.location, statuses, time.Now())
+		if !ok {
+			return
+		}
+		firstStatus := !agent.Resolved
+		agent.Resolved = true
+		if status == agent.LastStatus() {
+			if firstStatus && !status {
+				s.openMissingIncident(endpoint, s.decidingChecks(endpoint, status, deciding))
+			}
+			return
+		}
+		log.Println(endpoint.URL, "endpoint is", statusLabel(status), "from", locationNames(deciding))
+		updateStatus(agent, endpoint, status, s.decidingChecks(endpoint, status, deciding))
+	}
 
-		resp, err := http.Post(fmt.Sprintf("%s/%v", s.webhookURL, endpointID), "application/json", bytes.NewBuffer(jsonPayload))
+	// each run performs one check, the retry cycle is carried over between runs on the agent
+	return func(ctx context.Context, agent *model.HealthCheckAgent) {
+		endpoint := agent.Endpoint()
+		if endpoint.Distributed(s.location) {
+			distributedRun(ctx, agent, endpoint)
+			return
+		}
+		checkLog, err := healthCheck(ctx, agent, endpoint, agent.Tries+1)
+		if ctx.Err() != nil {
+			// interrupted by shutdown, not a failure of the endpoint
+			return
+		}
+		defer s.observeCheck(agent, endpoint, checkLog)
 		if err != nil {
-			log.Println("failed to send webhook, err:", err.Error())
+			agent.Tries++
+			agent.FailedChecks = append(agent.FailedChecks, checkLog)
+			agent.RecordRun(time.Now(), agent.ConsecutiveFailures()+1)
+			log.Println(endpoint.URL, "health check failed, try ", agent.Tries, ", err:", err.Error())
+			if agent.Tries >= endpoint.Retries {
+				agent.Tries = 0
+				log.Println(endpoint.URL, "endpoint is unhealthy")
+				if agent.LastStatus() {
+					updateStatus(agent, endpoint, false, agent.FailedChecks)
+				} else if !agent.Resolved {
+					s.openMissingIncident(endpoint, agent.FailedChecks)
+				}
+				agent.Resolved = true
+				agent.FailedChecks = nil
+			}
 			return
 		}
+		agent.Tries = 0
+		agent.FailedChecks = nil
+		agent.Resolved = true
+		agent.RecordRun(time.Now(), 0)
+		if !agent.LastStatus() {
+			updateStatus(agent, endpoint, true, []*model.CheckLog{checkLog})
+		}
+		log.Println(endpoint.URL, "endpoint is healthy")
+	}
+}
 
-		if resp.StatusCode != http.StatusOK {
-			log.Println("webhook failed, status:", resp.StatusCode)
-			return
+// quorumStatus decides the status of a distributed endpoint from the statuses of its locations:
+// it is down when a quorum of them see it down. deciding holds the locations that see it down
+// when it is down and the ones that see it up otherwise. Without a location that checked the
+// endpoint recently no decision is made.
+func quorumStatus(endpoint *model.Endpoint, server string, statuses []*model.LocationStatus, now time.Time) (status bool, deciding []*model.LocationStatus, ok bool) {
+	var up, down []*model.LocationStatus
+	for _, locationStatus := range statuses {
+		if !slices.Contains(endpoint.CheckedFrom(server), locationStatus.Location) || !locationStatus.Fresh(endpoint.Interval, now) {
+			continue
 		}
+		if locationStatus.Down(endpoint.Retries) {
+			down = append(down, locationStatus)
+		} else {
+			up = append(up, locationStatus)
+		}
+	}
+	if len(down) >= endpoint.QuorumSize(server) {
+		return false, down, true
 	}
+	return true, up, len(up) > 0
+}
 
-	updateStatus := func(endpoint *model.Endpoint, status bool) {
-		endpoint.LastStatus = status
+// decidingChecks returns the latest checks of the locations that decided a status transition,
+// or a stand-in when they cannot be fetched.
+func (s *endpointService) decidingChecks(endpoint *model.Endpoint, status bool, deciding []*model.LocationStatus) []*model.CheckLog {
+	ids := make([]uint, 0, len(deciding))
+	for _, locationStatus := range deciding {
+		ids = append(ids, locationStatus.LastCheckLogID)
+	}
+	checks, err := s.checkLogRepo.FetchByIDs(ids)
+	if err == nil && len(checks) > 0 {
+		return checks
+	}
 
-		if err := s.endpointRepo.UpdateLastStatus(endpoint.ID, status); err != nil {
-			log.Println("failed to update last status for endpoint ", endpoint.ID, ", err:", err.Error())
+	check := &model.CheckLog{TeamID: endpoint.TeamID, EndpointID: endpoint.ID}
+	check.CreatedAt = time.Now()
+	if !status {
+		check.Error = "endpoint is down from " + locationNames(deciding)
+		check.ErrorClass = model.CheckErrorUnknown
+	}
+	return []*model.CheckLog{check}
+}
+
+func locationNames(statuses []*model.LocationStatus) string {
+	names := make([]string, 0, len(statuses))
+	for _, locationStatus := range statuses {
+		names = append(names, locationStatus.Location)
+	}
+	return strings.Join(names, ", ")
+}
+
+func statusLabel(status bool) string {
+	if status {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// openIncident records the start of an outage from the failed checks that made the endpoint unhealthy.
+func (s *endpointService) openIncident(endpoint *model.Endpoint, failedChecks []*model.CheckLog) uint {
+	incident := &model.Incident{
+		TeamID:     endpoint.TeamID,
+		EndpointID: endpoint.ID,
+		StartedAt:  time.Now(),
+	}
+
+	ids := make([]uint, 0, len(failedChecks))
+	for _, checkLog := range failedChecks {
+		ids = append(ids, checkLog.ID)
+	}
+	if len(failedChecks) > 0 {
+		incident.StartedAt = failedChecks[0].CreatedAt
+		incident.FailureReason = failedChecks[0].Error
+	}
+	triggerIDs, err := json.Marshal(ids)
+	if err != nil {
+		log.Println("failed to marshal incident trigger check logs, err:", err.Error())
+	}
+	incident.TriggerCheckLogIDs = string(triggerIDs)
+
+	if err := s.incidentRepo.Create(incident); err != nil {
+		log.Println("failed to open incident for endpoint ", endpoint.ID, ", err:", err.Error())
+	}
+	return incident.ID
+}
+
+// openMissingIncident opens an incident for an endpoint found down without a transition, such
+// as one down since it was created or before a restart, unless one is open already.
+func (s *endpointService) openMissingIncident(endpoint *model.Endpoint, failedChecks []*model.CheckLog) {
+	if _, err := s.incidentRepo.FetchOpenByEndpointID(endpoint.ID); !errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	s.openIncident(endpoint, failedChecks)
+}
+
+// closeIncident resolves the open incident of an endpoint, if any, and returns its id.
+func (s *endpointService) closeIncident(endpoint *model.Endpoint) uint {
+	incident, err := s.incidentRepo.FetchOpenByEndpointID(endpoint.ID)
+	if err != nil {
+		return 0
+	}
+
+	endedAt := time.Now()
+	duration := int(endedAt.Sub(incident.StartedAt).Seconds())
+	if err := s.incidentRepo.Close(incident.ID, endedAt, duration); err != nil {
+		log.Println("failed to close incident ", incident.ID, ", err:", err.Error())
+	}
+	return incident.ID
+}
+
+// prepareEndpoint decodes the json encoded columns of an endpoint into their runtime fields.
+func prepareEndpoint(endpoint *model.Endpoint) error {
+	if endpoint.Timeout == 0 {
+		// endpoints created before timeouts were configurable
+		endpoint.Timeout = min(model.DefaultTimeout, endpoint.Interval)
+	}
+
+	headers := []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}{}
+	if endpoint.HTTPRequestHeaders != "" {
+		if err := json.Unmarshal([]byte(endpoint.HTTPRequestHeaders), &headers); err != nil {
+			return err
 		}
+	}
+	endpoint.Headers = make(map[string]string)
+	for i := range headers {
+		endpoint.Headers[headers[i].Key] = headers[i].Value
+	}
 
-		webhook(endpoint.ID, status)
+	endpoint.Criteria = model.SuccessCriteria{}
+	if endpoint.SuccessCriteria != "" {
+		if err := json.Unmarshal([]byte(endpoint.SuccessCriteria), &endpoint.Criteria); err != nil {
+			return err
+		}
+	}
+	if err := endpoint.Criteria.Validate(); err != nil {
+		return err
 	}
 
-	return func(ctx context.Context, wg *sync.WaitGroup, endpoint *model.Endpoint) {
-		defer wg.Done()
-		tries := 0
-		for {
-			select {
-			case <-ctx.Done():
-				log.Println(endpoint.URL, "health check agent is shutting down")
-				return
-			case <-time.After(time.Duration(endpoint.Interval) * time.Second):
-				err := healthCheck(endpoint)
-				if err != nil {
-					tries++
-					log.Println(endpoint.URL, "health check failed, try ", tries, ", err:", err.Error())
-					if tries >= endpoint.Retries {
-						tries = 0
-						log.Println(endpoint.URL, "endpoint is unhealthy")
-						if endpoint.LastStatus {
-							updateStatus(endpoint, false)
-						}
-					}
-					continue
-				}
-				tries = 0
-				if !endpoint.LastStatus {
-					updateStatus(endpoint, true)
-				}
-				log.Println(endpoint.URL, "endpoint is healthy")
-			}
+	endpoint.Check = model.CheckConfig{}
+	if endpoint.CheckConfig != "" {
+		if err := json.Unmarshal([]byte(endpoint.CheckConfig), &endpoint.Check); err != nil {
+			return err
 		}
 	}
+	if err := endpoint.ValidateCheck(); err != nil {
+		return err
+	}
+
+	endpoint.LocationNames = nil
+	if endpoint.Locations != "" {
+		if err := json.Unmarshal([]byte(endpoint.Locations), &endpoint.LocationNames); err != nil {
+			return err
+		}
+	}
+	return endpoint.ValidateLocations()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
 }
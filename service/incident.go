@@ -0,0 +1,64 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"time"
+)
+
+type IncidentService interface {
+	FetchIncidents(scope repository.Scope, filter repository.IncidentFilter) ([]*model.Incident, error)
+	GetIncident(scope repository.Scope, id uint) (*model.Incident, error)
+	AcknowledgeIncident(scope repository.Scope, id uint, by string) error
+	AnnotateIncident(scope repository.Scope, id uint, author, text string) error
+}
+
+type incidentService struct {
+	incidentRepo repository.IncidentRepository
+}
+
+func NewIncidentService(incidentRepo repository.IncidentRepository) IncidentService {
+	return &incidentService{incidentRepo}
+}
+
+func (s *incidentService) FetchIncidents(scope repository.Scope, filter repository.IncidentFilter) ([]*model.Incident, error) {
+	incidents, err := s.incidentRepo.FetchAll(scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return incidents, nil
+}
+
+func (s *incidentService) GetIncident(scope repository.Scope, id uint) (*model.Incident, error) {
+	incident, err := s.incidentRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (s *incidentService) AcknowledgeIncident(scope repository.Scope, id uint, by string) error {
+	if _, err := s.incidentRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.incidentRepo.Acknowledge(id, by, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *incidentService) AnnotateIncident(scope repository.Scope, id uint, author, text string) error {
+	if _, err := s.incidentRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.incidentRepo.CreateAnnotation(&model.IncidentAnnotation{IncidentID: id, Author: author, Text: text}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,752 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"log"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest declares endpoints, notification channels and groups, which are identified by
+// their names.
+type Manifest struct {
+	Groups    []ManifestGroup    `json:"groups,omitempty"`
+	Channels  []ManifestChannel  `json:"channels,omitempty"`
+	Endpoints []ManifestEndpoint `json:"endpoints,omitempty"`
+}
+
+type ManifestGroup struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type ManifestChannel struct {
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Config  model.ChannelConfig `json:"config"`
+	Enabled *bool               `json:"enabled,omitempty"` // defaults to true
+}
+
+type ManifestEndpoint struct {
+	Name               string                 `json:"name"`
+	Group              string                 `json:"group,omitempty"`
+	CheckType          string                 `json:"check_type,omitempty"`
+	URL                string                 `json:"url"`
+	Interval           int                    `json:"interval"`
+	Timeout            int                    `json:"timeout,omitempty"`
+	Retries            int                    `json:"retries"`
+	HTTPMethod         string                 `json:"http_method,omitempty"`
+	HTTPRequestHeaders map[string]string      `json:"http_request_headers,omitempty"`
+	HTTPRequestBody    any                    `json:"http_request_body,omitempty"`
+	SuccessCriteria    *model.SuccessCriteria `json:"success_criteria,omitempty"`
+	CheckConfig        *model.CheckConfig     `json:"check_config,omitempty"`
+	FreshConnection    bool                   `json:"fresh_connection,omitempty"`
+	Proxy              string                 `json:"proxy,omitempty"`
+	DNSResolver        string                 `json:"dns_resolver,omitempty"`
+	RedirectPolicy     string                 `json:"redirect_policy,omitempty"`
+	MaxRedirects       int                    `json:"max_redirects,omitempty"`
+	CABundle           string                 `json:"ca_bundle,omitempty"`
+	ClientCert         string                 `json:"client_cert,omitempty"`
+	ClientKey          string                 `json:"client_key,omitempty"`
+	InsecureSkipVerify bool                   `json:"insecure_skip_verify,omitempty"`
+	Public             bool                   `json:"public,omitempty"`
+	Locations          []string               `json:"locations,omitempty"`
+	Quorum             int                    `json:"quorum,omitempty"`
+	Active             *bool                  `json:"active,omitempty"` // defaults to true
+	Channels           []string               `json:"channels,omitempty"`
+}
+
+// ManifestReport lists what applying a manifest changed, as kind:name entries.
+type ManifestReport struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// ParseManifest decodes a YAML or JSON manifest, unknown fields are rejected.
+func ParseManifest(data []byte) (*Manifest, error) {
+	// decode through json so that the json tags of the embedded models apply to yaml too
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// EncodeManifest encodes the manifest as "yaml" or "json".
+func EncodeManifest(manifest *Manifest, format string) ([]byte, error) {
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == "json" {
+		return encoded, nil
+	}
+
+	// json is yaml, decoding it into a node keeps the field order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(encoded, &doc); err != nil {
+		return nil, err
+	}
+	blockStyle(&doc)
+	return yaml.Marshal(&doc)
+}
+
+// blockStyle drops the json flow style and quoting so that the node is encoded as plain yaml.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+type ManifestService interface {
+	// ApplyManifest and ExportManifest work on the groups, channels and endpoints of the team
+	// of the scope.
+	ApplyManifest(scope repository.Scope, manifest *Manifest, prune bool) (*ManifestReport, error)
+	ExportManifest(scope repository.Scope) (*Manifest, error)
+}
+
+type manifestService struct {
+	endpointService     EndpointService
+	notificationService NotificationService
+	groupRepo           repository.EndpointGroupRepository
+	teamRepo            repository.TeamRepository
+}
+
+func NewManifestService(
+	endpointService EndpointService,
+	notificationService NotificationService,
+	groupRepo repository.EndpointGroupRepository,
+	teamRepo repository.TeamRepository,
+) ManifestService {
+	return &manifestService{endpointService, notificationService, groupRepo, teamRepo}
+}
+
+// ApplyManifest reconciles the database with the manifest: declared groups, channels and
+// endpoints are created or updated, and with prune the ones that are not declared are deleted.
+// The manifest is validated as a whole, quotas of the team included, before anything is changed.
+func (s *manifestService) ApplyManifest(scope repository.Scope, manifest *Manifest, prune bool) (*ManifestReport, error) {
+	if err := s.validate(scope, manifest, prune); err != nil {
+		return nil, err
+	}
+
+	report := &ManifestReport{Created: []string{}, Updated: []string{}, Deleted: []string{}}
+
+	groupIDs, err := s.applyGroups(scope, manifest, report)
+	if err != nil {
+		return report, err
+	}
+
+	channelIDs, err := s.applyChannels(scope, manifest, report)
+	if err != nil {
+		return report, err
+	}
+
+	if err := s.applyEndpoints(scope, manifest, groupIDs, channelIDs, prune, report); err != nil {
+		return report, err
+	}
+
+	if prune {
+		if err := s.pruneChannels(scope, manifest, channelIDs, report); err != nil {
+			return report, err
+		}
+		if err := s.pruneGroups(manifest, groupIDs, report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// validate checks the manifest before it is applied. With prune the endpoints may only refer to
+// declared groups and channels, the others are deleted once the endpoints are applied.
+func (s *manifestService) validate(scope repository.Scope, manifest *Manifest, prune bool) error {
+	knownGroups := make(map[string]bool)
+	if !prune {
+		groups, err := s.groupRepo.FetchAll(scope)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			knownGroups[group.Name] = true
+		}
+	}
+	for i, group := range manifest.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("group %d: name is required", i+1)
+		}
+		knownGroups[group.Name] = true
+	}
+	if err := uniqueNames("group", len(manifest.Groups), func(i int) string { return manifest.Groups[i].Name }); err != nil {
+		return err
+	}
+
+	knownChannels := make(map[string]bool)
+	if !prune {
+		channels, err := s.notificationService.FetchAllChannels(scope)
+		if err != nil {
+			return err
+		}
+		for _, channel := range channels {
+			knownChannels[channel.Name] = true
+		}
+	}
+	for i, channel := range manifest.Channels {
+		if channel.Name == "" {
+			return fmt.Errorf("channel %d: name is required", i+1)
+		}
+		if err := channel.Config.Validate(model.ChannelType(channel.Type)); err != nil {
+			return fmt.Errorf("channel %s: %w", channel.Name, err)
+		}
+		knownChannels[channel.Name] = true
+	}
+	if err := uniqueNames("channel", len(manifest.Channels), func(i int) string { return manifest.Channels[i].Name }); err != nil {
+		return err
+	}
+
+	for i, endpoint := range manifest.Endpoints {
+		if endpoint.Name == "" {
+			return fmt.Errorf("endpoint %d: name is required", i+1)
+		}
+		if endpoint.Group != "" && !knownGroups[endpoint.Group] {
+			return fmt.Errorf("endpoint %s: unknown group %s", endpoint.Name, endpoint.Group)
+		}
+		for _, channel := range endpoint.Channels {
+			if !knownChannels[channel] {
+				return fmt.Errorf("endpoint %s: unknown channel %s", endpoint.Name, channel)
+			}
+		}
+		params, err := endpoint.params(nil)
+		if err != nil {
+			return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+		}
+		if err := params.apply(&model.Endpoint{}); err != nil {
+			return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+		}
+	}
+	if err := uniqueNames("endpoint", len(manifest.Endpoints), func(i int) string { return manifest.Endpoints[i].Name }); err != nil {
+		return err
+	}
+
+	return s.validateQuotas(scope, manifest, prune)
+}
+
+// validateQuotas checks the declared endpoints against the quotas of the team, counting the
+// endpoints that are kept without being declared unless they are pruned.
+func (s *manifestService) validateQuotas(scope repository.Scope, manifest *Manifest, prune bool) error {
+	team, err := s.teamRepo.FetchByID(scope.TeamID)
+	if err != nil {
+		return err
+	}
+	for _, endpoint := range manifest.Endpoints {
+		if err := team.ValidateInterval(endpoint.Interval); err != nil {
+			return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+		}
+	}
+
+	count := len(manifest.Endpoints)
+	if !prune {
+		endpoints, err := s.endpointService.FetchAllEndpoints(scope)
+		if err != nil {
+			return err
+		}
+		declared := make(map[string]bool, len(manifest.Endpoints))
+		for _, endpoint := range manifest.Endpoints {
+			declared[endpoint.Name] = true
+		}
+		for _, endpoint := range endpoints {
+			if !declared[endpointLabel(endpoint)] {
+				count++
+			}
+		}
+	}
+	if !team.AllowsEndpoints(count) {
+		return model.ErrEndpointQuota
+	}
+	return nil
+}
+
+func uniqueNames(kind string, n int, name func(i int) string) error {
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		if seen[name(i)] {
+			return fmt.Errorf("duplicate %s %s", kind, name(i))
+		}
+		seen[name(i)] = true
+	}
+	return nil
+}
+
+func (s *manifestService) applyGroups(scope repository.Scope, manifest *Manifest, report *ManifestReport) (map[string]uint, error) {
+	groups, err := s.groupRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint, len(groups))
+	existing := make(map[string]*model.EndpointGroup, len(groups))
+	for _, group := range groups {
+		ids[group.Name] = group.ID
+		existing[group.Name] = group
+	}
+
+	for _, declared := range manifest.Groups {
+		group, ok := existing[declared.Name]
+		if !ok {
+			group = &model.EndpointGroup{TeamID: scope.TeamID, Name: declared.Name, Description: declared.Description}
+			if err := s.groupRepo.Create(group); err != nil {
+				return nil, err
+			}
+			ids[group.Name] = group.ID
+			report.Created = append(report.Created, "group:"+group.Name)
+			continue
+		}
+		if group.Description != declared.Description {
+			group.Description = declared.Description
+			if err := s.groupRepo.Update(group); err != nil {
+				return nil, err
+			}
+			report.Updated = append(report.Updated, "group:"+group.Name)
+		}
+	}
+	return ids, nil
+}
+
+func (s *manifestService) applyChannels(scope repository.Scope, manifest *Manifest, report *ManifestReport) (map[string]uint, error) {
+	channels, err := s.notificationService.FetchAllChannels(scope)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*model.NotificationChannel, len(channels))
+	for _, channel := range channels {
+		existing[channel.Name] = channel
+	}
+
+	// channels are created enabled, the disabled ones are updated once they have an id
+	var disabled []ManifestChannel
+	for _, declared := range manifest.Channels {
+		config, err := json.Marshal(declared.Config)
+		if err != nil {
+			return nil, err
+		}
+		enabled := declared.Enabled == nil || *declared.Enabled
+
+		channel, ok := existing[declared.Name]
+		if !ok {
+			if err := s.notificationService.CreateChannel(scope, declared.Name, declared.Type, string(config)); err != nil {
+				return nil, err
+			}
+			report.Created = append(report.Created, "channel:"+declared.Name)
+			if !enabled {
+				disabled = append(disabled, declared)
+			}
+			continue
+		}
+		if string(channel.Type) == declared.Type && channel.Config == string(config) && channel.Enabled == enabled {
+			continue
+		}
+		if err := s.notificationService.UpdateChannel(scope, channel.ID, declared.Name, declared.Type, string(config), enabled); err != nil {
+			return nil, err
+		}
+		report.Updated = append(report.Updated, "channel:"+declared.Name)
+	}
+
+	channels, err = s.notificationService.FetchAllChannels(scope)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint, len(channels))
+	for _, channel := range channels {
+		ids[channel.Name] = channel.ID
+	}
+
+	for _, declared := range disabled {
+		config, err := json.Marshal(declared.Config)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.notificationService.UpdateChannel(scope, ids[declared.Name], declared.Name, declared.Type, string(config), false); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func (s *manifestService) applyEndpoints(
+	scope repository.Scope,
+	manifest *Manifest,
+	groupIDs, channelIDs map[string]uint,
+	prune bool,
+	report *ManifestReport,
+) error {
+	endpoints, err := s.endpointService.FetchAllEndpoints(scope)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*model.Endpoint, len(endpoints))
+	for _, endpoint := range endpoints {
+		existing[endpointLabel(endpoint)] = endpoint
+	}
+
+	declared := make(map[string]bool, len(manifest.Endpoints))
+	for _, declaredEndpoint := range manifest.Endpoints {
+		declared[declaredEndpoint.Name] = true
+
+		var groupID *uint
+		if declaredEndpoint.Group != "" {
+			id := groupIDs[declaredEndpoint.Group]
+			groupID = &id
+		}
+		params, err := declaredEndpoint.params(groupID)
+		if err != nil {
+			return err
+		}
+
+		endpoint, ok := existing[declaredEndpoint.Name]
+		if !ok {
+			if endpoint, err = s.endpointService.CreateEndpoint(scope, params); err != nil {
+				return fmt.Errorf("endpoint %s: %w", declaredEndpoint.Name, err)
+			}
+			report.Created = append(report.Created, "endpoint:"+endpoint.Name)
+		} else if !sameParams(NewEndpointParams(endpoint), normalizeParams(params)) {
+			if err := s.endpointService.UpdateEndpoint(scope, endpoint.ID, params); err != nil {
+				return fmt.Errorf("endpoint %s: %w", declaredEndpoint.Name, err)
+			}
+			report.Updated = append(report.Updated, "endpoint:"+endpoint.Name)
+		}
+
+		subscribed, err := s.applySubscriptions(scope, endpoint.ID, declaredEndpoint.Channels, channelIDs)
+		if err != nil {
+			return err
+		}
+		if subscribed && ok && !containsString(report.Updated, "endpoint:"+endpoint.Name) {
+			report.Updated = append(report.Updated, "endpoint:"+endpoint.Name)
+		}
+
+		active := declaredEndpoint.Active == nil || *declaredEndpoint.Active
+		if active != endpoint.ActiveCheck {
+			if err := s.endpointService.UpdateEndpointActivationStatus(scope, endpoint.ID, active); err != nil {
+				return fmt.Errorf("endpoint %s: %w", declaredEndpoint.Name, err)
+			}
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	for _, endpoint := range endpoints {
+		if declared[endpointLabel(endpoint)] {
+			continue
+		}
+		if endpoint.ActiveCheck {
+			if err := s.endpointService.UpdateEndpointActivationStatus(scope, endpoint.ID, false); err != nil {
+				return err
+			}
+		}
+		if err := s.endpointService.DeleteEndpoint(scope, endpoint.ID); err != nil {
+			return err
+		}
+		report.Deleted = append(report.Deleted, fmt.Sprintf("endpoint:%s", endpointLabel(endpoint)))
+	}
+	return nil
+}
+
+// applySubscriptions subscribes the endpoint to exactly the named channels, it reports
+// whether anything changed.
+func (s *manifestService) applySubscriptions(scope repository.Scope, endpointID uint, names []string, channelIDs map[string]uint) (bool, error) {
+	current, err := s.notificationService.FetchEndpointChannels(scope, endpointID)
+	if err != nil {
+		return false, err
+	}
+	wanted := make(map[uint]bool, len(names))
+	for _, name := range names {
+		wanted[channelIDs[name]] = true
+	}
+
+	changed := false
+	for _, channel := range current {
+		if wanted[channel.ID] {
+			delete(wanted, channel.ID)
+			continue
+		}
+		if err := s.notificationService.Unsubscribe(scope, endpointID, channel.ID); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	for channelID := range wanted {
+		if err := s.notificationService.Subscribe(scope, endpointID, channelID); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func (s *manifestService) pruneChannels(scope repository.Scope, manifest *Manifest, channelIDs map[string]uint, report *ManifestReport) error {
+	declared := make(map[string]bool, len(manifest.Channels))
+	for _, channel := range manifest.Channels {
+		declared[channel.Name] = true
+	}
+	for _, name := range sortedKeys(channelIDs) {
+		if declared[name] {
+			continue
+		}
+		if err := s.notificationService.DeleteChannel(scope, channelIDs[name]); err != nil {
+			return err
+		}
+		report.Deleted = append(report.Deleted, "channel:"+name)
+	}
+	return nil
+}
+
+func (s *manifestService) pruneGroups(manifest *Manifest, groupIDs map[string]uint, report *ManifestReport) error {
+	declared := make(map[string]bool, len(manifest.Groups))
+	for _, group := range manifest.Groups {
+		declared[group.Name] = true
+	}
+	for _, name := range sortedKeys(groupIDs) {
+		if declared[name] {
+			continue
+		}
+		if err := s.groupRepo.Delete(groupIDs[name]); err != nil {
+			return err
+		}
+		report.Deleted = append(report.Deleted, "group:"+name)
+	}
+	return nil
+}
+
+// ExportManifest describes the current groups, channels and endpoints, endpoints without a
+// name are exported under a generated one so that the manifest can be applied back.
+func (s *manifestService) ExportManifest(scope repository.Scope) (*Manifest, error) {
+	manifest := &Manifest{}
+
+	groups, err := s.groupRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+	groupNames := make(map[uint]string, len(groups))
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+		manifest.Groups = append(manifest.Groups, ManifestGroup{Name: group.Name, Description: group.Description})
+	}
+
+	channels, err := s.notificationService.FetchAllChannels(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		if err := prepareChannel(channel); err != nil {
+			log.Println("failed to prepare channel ", channel.ID, ", err:", err.Error())
+			continue
+		}
+		enabled := channel.Enabled
+		manifest.Channels = append(manifest.Channels, ManifestChannel{
+			Name:    channel.Name,
+			Type:    string(channel.Type),
+			Config:  channel.Options,
+			Enabled: &enabled,
+		})
+	}
+
+	endpoints, err := s.endpointService.FetchAllEndpoints(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		declared, err := newManifestEndpoint(endpoint)
+		if err != nil {
+			log.Println("failed to export endpoint ", endpoint.ID, ", err:", err.Error())
+			continue
+		}
+		if endpoint.GroupID != nil {
+			declared.Group = groupNames[*endpoint.GroupID]
+		}
+
+		subscribed, err := s.notificationService.FetchEndpointChannels(scope, endpoint.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, channel := range subscribed {
+			declared.Channels = append(declared.Channels, channel.Name)
+		}
+		sort.Strings(declared.Channels)
+
+		manifest.Endpoints = append(manifest.Endpoints, declared)
+	}
+	sort.Slice(manifest.Endpoints, func(i, j int) bool { return manifest.Endpoints[i].Name < manifest.Endpoints[j].Name })
+
+	return manifest, nil
+}
+
+func (e *ManifestEndpoint) params(groupID *uint) (EndpointParams, error) {
+	params := EndpointParams{
+		Name:               e.Name,
+		GroupID:            groupID,
+		CheckType:          e.CheckType,
+		URL:                e.URL,
+		HTTPMethod:         e.HTTPMethod,
+		Interval:           e.Interval,
+		Timeout:            e.Timeout,
+		Retries:            e.Retries,
+		FreshConnection:    e.FreshConnection,
+		Proxy:              e.Proxy,
+		DNSResolver:        e.DNSResolver,
+		RedirectPolicy:     e.RedirectPolicy,
+		MaxRedirects:       e.MaxRedirects,
+		CABundle:           e.CABundle,
+		ClientCert:         e.ClientCert,
+		ClientKey:          e.ClientKey,
+		InsecureSkipVerify: e.InsecureSkipVerify,
+		Public:             e.Public,
+		Quorum:             e.Quorum,
+	}
+
+	type httpHeader struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	headers := make([]httpHeader, 0, len(e.HTTPRequestHeaders))
+	for _, key := range sortedKeys(e.HTTPRequestHeaders) {
+		headers = append(headers, httpHeader{key, e.HTTPRequestHeaders[key]})
+	}
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return params, err
+	}
+	params.HTTPRequestHeaders = string(encoded)
+
+	if encoded, err = json.Marshal(e.HTTPRequestBody); err != nil {
+		return params, err
+	}
+	params.HTTPRequestBody = string(encoded)
+
+	if e.SuccessCriteria != nil {
+		if encoded, err = json.Marshal(e.SuccessCriteria); err != nil {
+			return params, err
+		}
+		params.SuccessCriteria = string(encoded)
+	}
+
+	if e.CheckConfig != nil {
+		if encoded, err = json.Marshal(e.CheckConfig); err != nil {
+			return params, err
+		}
+		params.CheckConfig = string(encoded)
+	}
+
+	if len(e.Locations) > 0 {
+		if encoded, err = json.Marshal(e.Locations); err != nil {
+			return params, err
+		}
+		params.Locations = string(encoded)
+	}
+
+	return params, nil
+}
+
+func newManifestEndpoint(endpoint *model.Endpoint) (ManifestEndpoint, error) {
+	if err := prepareEndpoint(endpoint); err != nil {
+		return ManifestEndpoint{}, err
+	}
+
+	active := endpoint.ActiveCheck
+	declared := ManifestEndpoint{
+		Name:               endpointLabel(endpoint),
+		CheckType:          string(endpoint.CheckType),
+		URL:                endpoint.URL,
+		Interval:           endpoint.Interval,
+		Timeout:            endpoint.Timeout,
+		Retries:            endpoint.Retries,
+		HTTPMethod:         string(endpoint.HTTPMethod),
+		FreshConnection:    endpoint.FreshConnection,
+		Proxy:              endpoint.Proxy,
+		DNSResolver:        endpoint.DNSResolver,
+		RedirectPolicy:     string(endpoint.RedirectPolicy),
+		MaxRedirects:       endpoint.MaxRedirects,
+		CABundle:           endpoint.CABundle,
+		ClientCert:         endpoint.ClientCert,
+		ClientKey:          endpoint.ClientKey,
+		InsecureSkipVerify: endpoint.InsecureSkipVerify,
+		Public:             endpoint.Public,
+		Locations:          endpoint.LocationNames,
+		Quorum:             endpoint.Quorum,
+		Active:             &active,
+	}
+	if len(endpoint.Headers) > 0 {
+		declared.HTTPRequestHeaders = endpoint.Headers
+	}
+	if endpoint.HTTPRequestBody != "" {
+		if err := json.Unmarshal([]byte(endpoint.HTTPRequestBody), &declared.HTTPRequestBody); err != nil {
+			return declared, err
+		}
+	}
+	if endpoint.SuccessCriteria != "" {
+		declared.SuccessCriteria = &endpoint.Criteria
+	}
+	if endpoint.CheckConfig != "" {
+		declared.CheckConfig = &endpoint.Check
+	}
+	return declared, nil
+}
+
+// endpointLabel names an endpoint, falling back to its id for endpoints created without a name.
+func endpointLabel(endpoint *model.Endpoint) string {
+	if endpoint.Name != "" {
+		return endpoint.Name
+	}
+	return fmt.Sprintf("endpoint-%d", endpoint.ID)
+}
+
+// normalizeParams fills in the defaults that are applied when the endpoint is saved.
+func normalizeParams(params EndpointParams) EndpointParams {
+	endpoint := &model.Endpoint{}
+	if err := params.apply(endpoint); err != nil {
+		return params
+	}
+	return NewEndpointParams(endpoint)
+}
+
+// sameParams compares endpoint params, the group by value.
+func sameParams(a, b EndpointParams) bool {
+	if (a.GroupID == nil) != (b.GroupID == nil) || (a.GroupID != nil && *a.GroupID != *b.GroupID) {
+		return false
+	}
+	a.GroupID, b.GroupID = nil, nil
+	return a == b
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"strings"
+	"testing"
+)
+
+// the fakes embed the interfaces they implement, methods the validation should not call panic
+
+type fakeGroupRepository struct {
+	repository.EndpointGroupRepository
+	groups []*model.EndpointGroup
+}
+
+func (r *fakeGroupRepository) FetchAll(scope repository.Scope) ([]*model.EndpointGroup, error) {
+	return r.groups, nil
+}
+
+type fakeNotificationService struct {
+	NotificationService
+	channels []*model.NotificationChannel
+}
+
+func (s *fakeNotificationService) FetchAllChannels(scope repository.Scope) ([]*model.NotificationChannel, error) {
+	return s.channels, nil
+}
+
+type fakeTeamRepository struct {
+	repository.TeamRepository
+}
+
+func (r *fakeTeamRepository) FetchByID(id uint) (*model.Team, error) {
+	return &model.Team{}, nil
+}
+
+type fakeEndpointService struct {
+	EndpointService
+}
+
+func (s *fakeEndpointService) FetchAllEndpoints(scope repository.Scope) ([]*model.Endpoint, error) {
+	return nil, nil
+}
+
+func TestManifestValidateReferences(t *testing.T) {
+	existingGroup := &model.EndpointGroup{Name: "legacy"}
+	existingChannel := &model.NotificationChannel{Name: "ops"}
+	service := &manifestService{
+		endpointService:     &fakeEndpointService{},
+		notificationService: &fakeNotificationService{channels: []*model.NotificationChannel{existingChannel}},
+		groupRepo:           &fakeGroupRepository{groups: []*model.EndpointGroup{existingGroup}},
+		teamRepo:            &fakeTeamRepository{},
+	}
+	endpoint := func(group string, channels ...string) ManifestEndpoint {
+		return ManifestEndpoint{Name: "api", URL: "https://example.com", Interval: 60, Group: group, Channels: channels}
+	}
+
+	tests := []struct {
+		name     string
+		manifest Manifest
+		prune    bool
+		wantErr  string
+	}{
+		{"existing group kept", Manifest{Endpoints: []ManifestEndpoint{endpoint("legacy")}}, false, ""},
+		{"existing channel kept", Manifest{Endpoints: []ManifestEndpoint{endpoint("", "ops")}}, false, ""},
+		{"existing group pruned", Manifest{Endpoints: []ManifestEndpoint{endpoint("legacy")}}, true, "unknown group legacy"},
+		{"existing channel pruned", Manifest{Endpoints: []ManifestEndpoint{endpoint("", "ops")}}, true, "unknown channel ops"},
+		{
+			"declared group and channel pruned",
+			Manifest{
+				Groups:    []ManifestGroup{{Name: "legacy"}},
+				Channels:  []ManifestChannel{{Name: "ops", Type: string(model.ChannelSlack), Config: model.ChannelConfig{URL: "https://hooks.example.com"}}},
+				Endpoints: []ManifestEndpoint{endpoint("legacy", "ops")},
+			},
+			true,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.validate(repository.TeamScope(1), &tt.manifest, tt.prune)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() err = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"healthcheck/pkg/metrics"
+	"healthcheck/pkg/notifier"
+	"log"
+	"sync"
+	"time"
+)
+
+const notificationTimeout = 10 * time.Second
+
+type NotificationService interface {
+	// CreateChannel creates the channel in the team of the scope.
+	CreateChannel(scope repository.Scope, name, channelType, config string) error
+	FetchAllChannels(scope repository.Scope) ([]*model.NotificationChannel, error)
+	GetChannel(scope repository.Scope, id uint) (*model.NotificationChannel, error)
+	UpdateChannel(scope repository.Scope, id uint, name, channelType, config string, enabled bool) error
+	DeleteChannel(scope repository.Scope, id uint) error
+	FetchEndpointChannels(scope repository.Scope, endpointID uint) ([]*model.NotificationChannel, error)
+	Subscribe(scope repository.Scope, endpointID, channelID uint) error
+	Unsubscribe(scope repository.Scope, endpointID, channelID uint) error
+	Notify(notification notifier.Notification)
+	FetchDeliveries(scope repository.Scope, filter repository.DeliveryFilter) ([]*model.NotificationDelivery, error)
+	GetDelivery(scope repository.Scope, id uint) (*model.NotificationDelivery, error)
+	Redeliver(scope repository.Scope, id uint) error
+	Shutdown()
+}
+
+type notificationService struct {
+	defaultWebhookURL    string
+	defaultWebhookSecret string
+	policy               DeliveryPolicy
+	cancel               context.CancelFunc
+	metrics              *metrics.Metrics
+	channelRepo          repository.NotificationChannelRepository
+	deliveryRepo         repository.NotificationDeliveryRepository
+	endpointRepo         repository.EndpointRepository
+}
+
+// NewNotificationService creates the notification service and starts dispatching queued
+// deliveries, defaultWebhookURL is used for endpoints that are not subscribed to any channel
+// and may be empty.
+func NewNotificationService(
+	defaultWebhookURL string,
+	defaultWebhookSecret string,
+	policy DeliveryPolicy,
+	wg *sync.WaitGroup,
+	metrics *metrics.Metrics,
+	channelRepo repository.NotificationChannelRepository,
+	deliveryRepo repository.NotificationDeliveryRepository,
+	endpointRepo repository.EndpointRepository,
+) NotificationService {
+	ctx, cancel := context.WithCancel(context.Background())
+	notificationService := &notificationService{
+		defaultWebhookURL,
+		defaultWebhookSecret,
+		policy,
+		cancel,
+		metrics,
+		channelRepo,
+		deliveryRepo,
+		endpointRepo,
+	}
+
+	wg.Add(1)
+	go notificationService.dispatch(ctx, wg)
+
+	return notificationService
+}
+
+func (s *notificationService) CreateChannel(scope repository.Scope, name, channelType, config string) error {
+	channel := &model.NotificationChannel{
+		TeamID:  scope.TeamID,
+		Name:    name,
+		Type:    model.ChannelType(channelType),
+		Config:  config,
+		Enabled: true,
+	}
+	if err := prepareChannel(channel); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Create(channel); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *notificationService) FetchAllChannels(scope repository.Scope) ([]*model.NotificationChannel, error) {
+	channels, err := s.channelRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		redactChannel(channel)
+	}
+
+	return channels, nil
+}
+
+func (s *notificationService) GetChannel(scope repository.Scope, id uint) (*model.NotificationChannel, error) {
+	channel, err := s.channelRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	redactChannel(channel)
+
+	return channel, nil
+}
+
+func (s *notificationService) UpdateChannel(scope repository.Scope, id uint, name, channelType, config string, enabled bool) error {
+	channel, err := s.channelRepo.FetchByID(scope, id)
+	if err != nil {
+		return err
+	}
+
+	// the secrets left blank are kept while the channel keeps its type
+	var previous model.ChannelConfig
+	if channel.Type == model.ChannelType(channelType) {
+		previous, _ = decodeChannelConfig(channel.Config)
+	}
+	options, err := decodeChannelConfig(config)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(options.WithSecrets(previous))
+	if err != nil {
+		return err
+	}
+
+	channel.Name = name
+	channel.Type = model.ChannelType(channelType)
+	channel.Config = string(encoded)
+	channel.Enabled = enabled
+	if err := prepareChannel(channel); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Update(channel); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *notificationService) DeleteChannel(scope repository.Scope, id uint) error {
+	if _, err := s.channelRepo.FetchByID(scope, id); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *notificationService) FetchEndpointChannels(scope repository.Scope, endpointID uint) ([]*model.NotificationChannel, error) {
+	channels, err := s.channelRepo.FetchByEndpointID(scope, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		redactChannel(channel)
+	}
+
+	return channels, nil
+}
+
+// Subscribe routes the notifications of an endpoint to a channel, both of the same team.
+func (s *notificationService) Subscribe(scope repository.Scope, endpointID, channelID uint) error {
+	endpoint, err := s.endpointRepo.FetchByID(scope, endpointID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.channelRepo.FetchByID(repository.TeamScope(endpoint.TeamID), channelID); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Subscribe(endpointID, channelID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *notificationService) Unsubscribe(scope repository.Scope, endpointID, channelID uint) error {
+	if _, err := s.endpointRepo.FetchByID(scope, endpointID); err != nil {
+		return err
+	}
+
+	if err := s.channelRepo.Unsubscribe(endpointID, channelID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Notify queues the status change of an endpoint for every enabled channel it is subscribed to.
+func (s *notificationService) Notify(notification notifier.Notification) {
+	endpoint, err := s.endpointRepo.FetchByID(repository.AllTeams, notification.EndpointID)
+	if err != nil {
+		log.Println("failed to fetch endpoint ", notification.EndpointID, " to notify, err:", err.Error())
+		return
+	}
+	scope := repository.TeamScope(endpoint.TeamID)
+
+	channels, err := s.channelRepo.FetchByEndpointID(scope, endpoint.ID)
+	if err != nil {
+		log.Println("failed to fetch notification channels for endpoint ", notification.EndpointID, ", err:", err.Error())
+		return
+	}
+
+	if len(channels) == 0 && s.defaultWebhookURL != "" {
+		s.enqueue(scope, 0, notification)
+		return
+	}
+
+	for _, channel := range channels {
+		if channel.Enabled {
+			s.enqueue(scope, channel.ID, notification)
+		}
+	}
+}
+
+func newNotifier(channel *model.NotificationChannel) notifier.Notifier {
+	options := channel.Options
+	switch channel.Type {
+	case model.ChannelSlack:
+		return notifier.NewSlackNotifier(options.URL)
+	case model.ChannelTeams:
+		return notifier.NewTeamsNotifier(options.URL)
+	case model.ChannelPagerDuty:
+		return notifier.NewPagerDutyNotifier(options.URL, options.RoutingKey)
+	case model.ChannelEmail:
+		return notifier.NewEmailNotifier(options.SMTPHost, options.SMTPPort, options.Username, options.Password, options.From, options.To)
+	default:
+		return notifier.NewWebhookNotifier(options.URL, options.Secret)
+	}
+}
+
+// prepareChannel decodes and validates the json encoded config of a channel.
+func prepareChannel(channel *model.NotificationChannel) error {
+	options, err := decodeChannelConfig(channel.Config)
+	channel.Options = options
+	if err != nil {
+		return err
+	}
+	return channel.Options.Validate(channel.Type)
+}
+
+// redactChannel decodes the config of a channel read by the API without its secrets.
+func redactChannel(channel *model.NotificationChannel) {
+	options, err := decodeChannelConfig(channel.Config)
+	if err != nil {
+		log.Println("failed to decode channel ", channel.ID, ", err:", err.Error())
+	}
+	channel.Options = options.Redacted()
+}
+
+func decodeChannelConfig(config string) (model.ChannelConfig, error) {
+	options := model.ChannelConfig{}
+	if config == "" {
+		return options, nil
+	}
+	if err := json.Unmarshal([]byte(config), &options); err != nil {
+		return model.ChannelConfig{}, err
+	}
+	return options, nil
+}
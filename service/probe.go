@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"log"
+	"slices"
+	"time"
+)
+
+var ErrInvalidProbeLocation = errors.New("location must be set and differ from the location of the server")
+
+// ProbeAssignment is a check run by the probes of a location, the params carry what the probe
+// needs to run it.
+type ProbeAssignment struct {
+	EndpointID uint           `json:"endpoint_id"`
+	Params     EndpointParams `json:"params"`
+}
+
+type ProbeService interface {
+	// FetchAssignments returns the active endpoints of every team that are checked from location.
+	FetchAssignments(location string) ([]ProbeAssignment, error)
+	// RecordResults stores the check logs reported by the probes of location and returns how many
+	// were accepted, the results of endpoints no longer checked from location are dropped.
+	RecordResults(location string, results []*model.CheckLog) (int, error)
+}
+
+type probeService struct {
+	location           string
+	bodyPolicy         BodyPolicy
+	endpointRepo       repository.EndpointRepository
+	checkLogRepo       repository.CheckLogRepository
+	locationStatusRepo repository.LocationStatusRepository
+}
+
+// NewProbeService serves the probes of a server whose own checks run from location.
+func NewProbeService(
+	location string,
+	bodyPolicy BodyPolicy,
+	endpointRepo repository.EndpointRepository,
+	checkLogRepo repository.CheckLogRepository,
+	locationStatusRepo repository.LocationStatusRepository,
+) ProbeService {
+	return &probeService{location, bodyPolicy, endpointRepo, checkLogRepo, locationStatusRepo}
+}
+
+func (s *probeService) FetchAssignments(location string) ([]ProbeAssignment, error) {
+	if location == "" || location == s.location {
+		return nil, ErrInvalidProbeLocation
+	}
+
+	endpoints, err := s.endpointRepo.FetchAll(repository.AllTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []ProbeAssignment{}
+	for _, endpoint := range endpoints {
+		if !s.assigned(endpoint, location) {
+			continue
+		}
+		assignments = append(assignments, ProbeAssignment{
+			EndpointID: endpoint.ID,
+			Params:     NewEndpointParams(endpoint),
+		})
+	}
+
+	return assignments, nil
+}
+
+func (s *probeService) RecordResults(location string, results []*model.CheckLog) (int, error) {
+	if location == "" || location == s.location {
+		return 0, ErrInvalidProbeLocation
+	}
+
+	endpoints := make(map[uint]*model.Endpoint)
+	now := time.Now()
+	accepted := 0
+	for _, checkLog := range results {
+		endpoint, ok := endpoints[checkLog.EndpointID]
+		if !ok {
+			endpoint, _ = s.endpointRepo.FetchByID(repository.AllTeams, checkLog.EndpointID)
+			endpoints[checkLog.EndpointID] = endpoint
+		}
+		if endpoint == nil || !s.assigned(endpoint, location) {
+			continue
+		}
+
+		checkLog.ID = 0
+		checkLog.TeamID = endpoint.TeamID
+		checkLog.Location = location
+		if checkLog.CreatedAt.IsZero() || checkLog.CreatedAt.After(now) {
+			checkLog.CreatedAt = now
+		}
+		s.bodyPolicy.apply(checkLog)
+		if err := s.checkLogRepo.Create(checkLog); err != nil {
+			return accepted, err
+		}
+		if err := s.locationStatusRepo.Record(checkLog); err != nil {
+			log.Println("failed to record location status for endpoint ", endpoint.ID, ", err:", err.Error())
+		}
+		accepted++
+	}
+
+	return accepted, nil
+}
+
+// assigned reports whether endpoint is actively checked from location.
+func (s *probeService) assigned(endpoint *model.Endpoint, location string) bool {
+	if !endpoint.ActiveCheck || prepareEndpoint(endpoint) != nil {
+		return false
+	}
+	return slices.Contains(endpoint.CheckedFrom(s.location), location)
+}
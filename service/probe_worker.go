@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	httpclient "healthcheck/pkg/http_client"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	probePushInterval   = time.Second
+	probeRequestTimeout = 30 * time.Second
+	// MaxProbeBatch is the most results a probe reports in one request.
+	MaxProbeBatch = 500
+	// probeMaxPending bounds the results kept while the server cannot be reached, the oldest
+	// are dropped first.
+	probeMaxPending = 10000
+)
+
+type ProbeWorker interface {
+	// Shutdown stops the checks and reports the results still pending.
+	Shutdown()
+}
+
+type probeWorker struct {
+	location     string
+	syncInterval time.Duration
+	client       *probeClient
+	runner       *checkRunner
+	agentRepo    repository.HealthCheckAgentRepository
+	cancel       context.CancelFunc
+	done         sync.WaitGroup
+
+	mu      sync.Mutex
+	pending []*model.CheckLog
+}
+
+// NewProbeWorker runs the checks that the server at serverURL assigns to location, with the API
+// key token of the probe role. Assignments are refreshed every syncInterval and results are
+// reported every second, they are kept and reported later while the server cannot be reached.
+func NewProbeWorker(
+	location string,
+	serverURL string,
+	token string,
+	syncInterval time.Duration,
+	httpClient *httpclient.Client,
+	checkTimeouts CheckTimeouts,
+	agentRepo repository.HealthCheckAgentRepository,
+) (ProbeWorker, error) {
+	if location == "" {
+		return nil, ErrInvalidProbeLocation
+	}
+	if _, err := url.ParseRequestURI(serverURL); err != nil {
+		return nil, fmt.Errorf("invalid probe server url: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	worker := &probeWorker{
+		location:     location,
+		syncInterval: syncInterval,
+		client: &probeClient{
+			serverURL:  strings.TrimSuffix(serverURL, "/"),
+			token:      token,
+			httpClient: &http.Client{Timeout: probeRequestTimeout},
+		},
+		runner:    &checkRunner{httpClient, checkTimeouts},
+		agentRepo: agentRepo,
+		cancel:    cancel,
+	}
+
+	if err := worker.sync(ctx); err != nil {
+		log.Println("failed to fetch the checks of location", location, ", err:", err.Error())
+	}
+
+	worker.done.Add(2)
+	go worker.syncLoop(ctx)
+	go worker.pushLoop(ctx)
+
+	return worker, nil
+}
+
+func (w *probeWorker) Shutdown() {
+	w.cancel()
+	w.done.Wait()
+	w.agentRepo.StopAll()
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeRequestTimeout)
+	defer cancel()
+	w.push(ctx)
+	if pending := w.pendingCount(); pending > 0 {
+		log.Println(pending, "check results could not be reported")
+	}
+}
+
+func (w *probeWorker) syncLoop(ctx context.Context) {
+	defer w.done.Done()
+	ticker := time.NewTicker(w.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.sync(ctx); err != nil {
+				log.Println("failed to fetch the checks of location", w.location, ", err:", err.Error())
+			}
+		}
+	}
+}
+
+// sync makes the agents match the assignments of the location, the agents of the endpoints no
+// longer assigned are removed.
+func (w *probeWorker) sync(ctx context.Context) error {
+	assignments, err := w.client.fetchAssignments(ctx, w.location)
+	if err != nil {
+		return err
+	}
+
+	assigned := make(map[uint]bool, len(assignments))
+	for _, assignment := range assignments {
+		endpoint := &model.Endpoint{}
+		endpoint.ID = assignment.EndpointID
+		if err := assignment.Params.apply(endpoint); err != nil {
+			log.Println("invalid check assigned for endpoint ", assignment.EndpointID, ", err:", err.Error())
+			continue
+		}
+		endpoint.ActiveCheck = true
+		assigned[endpoint.ID] = true
+		w.agentRepo.Sync(endpoint, w.check)
+		w.runner.bind(endpoint)
+	}
+	for _, agent := range w.agentRepo.List(repository.AllTeams) {
+		if !assigned[agent.ID] && w.agentRepo.Delete(agent.ID) {
+			w.runner.release(agent.ID)
+		}
+	}
+
+	return nil
+}
+
+// check runs one check of an agent and queues its result, the retry cycle of the agent only
+// numbers the attempts since the server decides the status of the endpoint.
+func (w *probeWorker) check(ctx context.Context, agent *model.HealthCheckAgent) {
+	endpoint := agent.Endpoint()
+	res, steps, err := w.runner.run(ctx, endpoint)
+	if ctx.Err() != nil {
+		return
+	}
+
+	checkLog := newCheckLog(endpoint, w.location, agent.Tries+1, res, steps, err)
+	checkLog.CreatedAt = time.Now()
+	if err != nil {
+		agent.Tries++
+		if agent.Tries >= endpoint.Retries {
+			agent.Tries = 0
+		}
+		agent.RecordRun(time.Now(), agent.ConsecutiveFailures()+1)
+		log.Println(endpoint.URL, "health check failed from", w.location, ", err:", err.Error())
+	} else {
+		agent.Tries = 0
+		agent.RecordRun(time.Now(), 0)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, checkLog)
+	if len(w.pending) > probeMaxPending {
+		w.pending = w.pending[len(w.pending)-probeMaxPending:]
+	}
+}
+
+func (w *probeWorker) pushLoop(ctx context.Context) {
+	defer w.done.Done()
+	ticker := time.NewTicker(probePushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.push(ctx)
+		}
+	}
+}
+
+// push reports the pending results batch by batch, a batch that fails is put back to be
+// reported at the next push.
+func (w *probeWorker) push(ctx context.Context) {
+	for {
+		w.mu.Lock()
+		batch := slices.Clone(w.pending[:min(len(w.pending), MaxProbeBatch)])
+		w.pending = w.pending[len(batch):]
+		w.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := w.client.pushResults(ctx, w.location, batch); err != nil {
+			log.Println("failed to report check results, err:", err.Error())
+			w.mu.Lock()
+			w.pending = append(batch, w.pending...)
+			if len(w.pending) > probeMaxPending {
+				w.pending = w.pending[len(w.pending)-probeMaxPending:]
+			}
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (w *probeWorker) pendingCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// probeClient calls the probe API of the server.
+type probeClient struct {
+	serverURL  string
+	token      string
+	httpClient *http.Client
+}
+
+func (c *probeClient) fetchAssignments(ctx context.Context, location string) ([]ProbeAssignment, error) {
+	var assignments []ProbeAssignment
+	path := "/api/v1/probes/checks?location=" + url.QueryEscape(location)
+	if err := c.do(ctx, http.MethodGet, path, nil, &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (c *probeClient) pushResults(ctx context.Context, location string, results []*model.CheckLog) error {
+	body := struct {
+		Location string            `json:"location"`
+		Results  []*model.CheckLog `json:"results"`
+	}{location, results}
+	return c.do(ctx, http.MethodPost, "/api/v1/probes/results", body, nil)
+}
+
+// do sends a request to the server and decodes the data of its response into out.
+func (c *probeClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.serverURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	response := struct {
+		Data   json.RawMessage `json:"data"`
+		Error  string          `json:"error"`
+		Result bool            `json:"result"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("unexpected response with status %d: %w", res.StatusCode, err)
+	}
+	if !response.Result {
+		return errors.New(response.Error)
+	}
+	if out != nil && len(response.Data) > 0 {
+		return json.Unmarshal(response.Data, out)
+	}
+	return nil
+}
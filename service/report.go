@@ -0,0 +1,271 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"time"
+)
+
+type EndpointStats struct {
+	EndpointID   uint      `json:"endpoint_id"`
+	URL          string    `json:"url"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	TotalChecks  int       `json:"total_checks"`
+	FailedChecks int       `json:"failed_checks"`
+	Uptime       float64   `json:"uptime"` // percentage of successful checks
+	Incidents    int       `json:"incidents"`
+	MTTR         float64   `json:"mttr"`        // in seconds
+	MTBF         float64   `json:"mtbf"`        // in seconds
+	LatencyP50   float64   `json:"latency_p50"` // in milliseconds
+	LatencyP95   float64   `json:"latency_p95"` // in milliseconds
+	LatencyP99   float64   `json:"latency_p99"` // in milliseconds
+}
+
+type AggregateStats struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	TotalChecks  int              `json:"total_checks"`
+	FailedChecks int              `json:"failed_checks"`
+	Uptime       float64          `json:"uptime"`
+	Incidents    int              `json:"incidents"`
+	Endpoints    []*EndpointStats `json:"endpoints"`
+}
+
+type ReportService interface {
+	EndpointStats(scope repository.Scope, id uint, from, to time.Time) (*EndpointStats, error)
+	AggregateStats(scope repository.Scope, from, to time.Time) (*AggregateStats, error)
+	// EndpointRollups returns the rollups of an endpoint, which outlive its check logs, with a
+	// bucket starting in [from, to]. They span every location when location is empty.
+	EndpointRollups(scope repository.Scope, id uint, resolution model.RollupResolution, location string, from, to time.Time) ([]*model.CheckRollup, error)
+}
+
+type reportService struct {
+	endpointRepo repository.EndpointRepository
+	checkLogRepo repository.CheckLogRepository
+	rollupRepo   repository.CheckRollupRepository
+}
+
+func NewReportService(
+	endpointRepo repository.EndpointRepository,
+	checkLogRepo repository.CheckLogRepository,
+	rollupRepo repository.CheckRollupRepository,
+) ReportService {
+	return &reportService{endpointRepo, checkLogRepo, rollupRepo}
+}
+
+func (s *reportService) EndpointStats(scope repository.Scope, id uint, from, to time.Time) (*EndpointStats, error) {
+	endpoint, err := s.endpointRepo.FetchByID(scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.endpointStats(scope, []*model.Endpoint{endpoint}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats[0], nil
+}
+
+func (s *reportService) AggregateStats(scope repository.Scope, from, to time.Time) (*AggregateStats, error) {
+	endpoints, err := s.endpointRepo.FetchAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.endpointStats(scope, endpoints, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := &AggregateStats{From: from, To: to, Endpoints: stats}
+	for _, endpointStats := range stats {
+		aggregate.TotalChecks += endpointStats.TotalChecks
+		aggregate.FailedChecks += endpointStats.FailedChecks
+		aggregate.Incidents += endpointStats.Incidents
+	}
+	aggregate.Uptime = uptime(aggregate.TotalChecks, aggregate.FailedChecks)
+
+	return aggregate, nil
+}
+
+func (s *reportService) EndpointRollups(scope repository.Scope, id uint, resolution model.RollupResolution, location string, from, to time.Time) ([]*model.CheckRollup, error) {
+	if err := resolution.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.endpointRepo.FetchByID(scope, id); err != nil {
+		return nil, err
+	}
+
+	rollups, err := s.rollupRepo.FetchByEndpointID(scope, resolution, id, location, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollups, nil
+}
+
+// endpointStats computes the stats of the endpoints from aggregates of their check logs, in the
+// order of endpoints. An incident is a run of consecutive failed checks long enough to mark an
+// endpoint as unhealthy. The checks of the part of the window whose check logs were pruned are
+// counted from the rollups, the incidents only from the check logs.
+func (s *reportService) endpointStats(scope repository.Scope, endpoints []*model.Endpoint, from, to time.Time) ([]*EndpointStats, error) {
+	ids := make([]uint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		ids = append(ids, endpoint.ID)
+	}
+
+	rawFrom, err := s.rawFrom(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rolledUp, err := s.summarizeRollups(scope, ids, from, rawFrom)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.checkLogRepo.Summarize(scope, ids, rawFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.checkLogRepo.FetchFailureRuns(scope, ids, rawFrom, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryByEndpoint := make(map[uint]repository.CheckLogSummary, len(summaries))
+	for _, summary := range summaries {
+		summaryByEndpoint[summary.EndpointID] = summary
+	}
+	totalByEndpoint := make(map[uint]repository.CheckLogSummary, len(summaries))
+	for _, summary := range append(rolledUp, summaries...) {
+		totalByEndpoint[summary.EndpointID] = mergeSummaries(totalByEndpoint[summary.EndpointID], summary)
+	}
+	runsByEndpoint := make(map[uint][]repository.FailureRun)
+	for _, run := range runs {
+		runsByEndpoint[run.EndpointID] = append(runsByEndpoint[run.EndpointID], run)
+	}
+
+	stats := make([]*EndpointStats, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpointStats := &EndpointStats{EndpointID: endpoint.ID, URL: endpoint.URL, From: from, To: to}
+		stats = append(stats, endpointStats)
+
+		total := totalByEndpoint[endpoint.ID]
+		endpointStats.TotalChecks = total.Total
+		endpointStats.FailedChecks = total.Failed
+		endpointStats.Uptime = uptime(total.Total, total.Failed)
+		endpointStats.LatencyP50 = total.LatencyP50
+		endpointStats.LatencyP95 = total.LatencyP95
+		endpointStats.LatencyP99 = total.LatencyP99
+
+		summary, ok := summaryByEndpoint[endpoint.ID]
+		if !ok {
+			continue
+		}
+
+		var downtime, repairTime time.Duration
+		repairedRuns := 0
+		for _, run := range runsByEndpoint[endpoint.ID] {
+			endpointStats.Incidents++
+			if run.RepairedAt == nil {
+				downtime += run.EndedAt.Sub(run.StartedAt)
+				continue
+			}
+			repairedRuns++
+			repairTime += run.RepairedAt.Sub(run.StartedAt)
+			downtime += run.RepairedAt.Sub(run.StartedAt)
+		}
+		if repairedRuns > 0 {
+			endpointStats.MTTR = (repairTime / time.Duration(repairedRuns)).Seconds()
+		}
+		if endpointStats.Incidents > 0 {
+			observed := summary.LastAt.Sub(summary.FirstAt)
+			endpointStats.MTBF = ((observed - downtime) / time.Duration(endpointStats.Incidents)).Seconds()
+		}
+	}
+
+	return stats, nil
+}
+
+// rawFrom returns where the check logs of the window start, the hour of the oldest check log
+// may have been pruned in part and is taken from the rollups.
+func (s *reportService) rawFrom(from, to time.Time) (time.Time, error) {
+	oldest, err := s.checkLogRepo.Oldest()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if oldest.IsZero() {
+		return to, nil
+	}
+	if !oldest.After(from) {
+		return from, nil
+	}
+	rawFrom := model.RollupHourly.Bucket(oldest).Add(time.Hour)
+	if rawFrom.After(to) {
+		return to, nil
+	}
+	return rawFrom, nil
+}
+
+// summarizeRollups aggregates the checks of [from, to) from the hourly rollups, and from the
+// daily rollups for the days before the oldest hourly rollup kept.
+func (s *reportService) summarizeRollups(scope repository.Scope, ids []uint, from, to time.Time) ([]repository.CheckLogSummary, error) {
+	if !from.Before(to) {
+		return nil, nil
+	}
+	oldestHour, err := s.rollupRepo.OldestBucket(model.RollupHourly)
+	if err != nil {
+		return nil, err
+	}
+	hourlyFrom := from
+	if oldestHour.IsZero() || oldestHour.After(from) {
+		// daily rollups cover whole days, the day of to is left to the hourly rollups
+		hourlyFrom = model.RollupDaily.Bucket(to)
+		if !oldestHour.IsZero() {
+			if afterOldest := model.RollupDaily.Bucket(oldestHour).Add(24 * time.Hour); afterOldest.Before(hourlyFrom) {
+				hourlyFrom = afterOldest
+			}
+		}
+		if hourlyFrom.Before(from) {
+			hourlyFrom = from
+		}
+	}
+
+	daily, err := s.rollupRepo.Summarize(scope, model.RollupDaily, ids, from, hourlyFrom)
+	if err != nil {
+		return nil, err
+	}
+	hourly, err := s.rollupRepo.Summarize(scope, model.RollupHourly, ids, hourlyFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(daily, hourly...), nil
+}
+
+// mergeSummaries adds up the checks of two summaries of an endpoint, the percentiles are
+// weighted by the checks of each.
+func mergeSummaries(a, b repository.CheckLogSummary) repository.CheckLogSummary {
+	total := a.Total + b.Total
+	if total == 0 {
+		return b
+	}
+	weighted := func(x, y float64) float64 {
+		return (x*float64(a.Total) + y*float64(b.Total)) / float64(total)
+	}
+	return repository.CheckLogSummary{
+		EndpointID: b.EndpointID,
+		Total:      total,
+		Failed:     a.Failed + b.Failed,
+		LatencyP50: weighted(a.LatencyP50, b.LatencyP50),
+		LatencyP95: weighted(a.LatencyP95, b.LatencyP95),
+		LatencyP99: weighted(a.LatencyP99, b.LatencyP99),
+	}
+}
+
+func uptime(total, failed int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(total-failed) / float64(total) * 100
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"testing"
+	"time"
+)
+
+type fakeEndpointRepository struct {
+	repository.EndpointRepository
+	endpoint *model.Endpoint
+}
+
+func (r *fakeEndpointRepository) FetchByID(scope repository.Scope, id uint) (*model.Endpoint, error) {
+	return r.endpoint, nil
+}
+
+// fakeCheckLogRepository holds the checks left after pruning, one summary for any window.
+type fakeCheckLogRepository struct {
+	repository.CheckLogRepository
+	oldest  time.Time
+	summary repository.CheckLogSummary
+	from    time.Time
+}
+
+func (r *fakeCheckLogRepository) Oldest() (time.Time, error) {
+	return r.oldest, nil
+}
+
+func (r *fakeCheckLogRepository) Summarize(scope repository.Scope, ids []uint, from, to time.Time) ([]repository.CheckLogSummary, error) {
+	r.from = from
+	if !from.Before(to) {
+		return nil, nil
+	}
+	return []repository.CheckLogSummary{r.summary}, nil
+}
+
+func (r *fakeCheckLogRepository) FetchFailureRuns(scope repository.Scope, ids []uint, from, to time.Time) ([]repository.FailureRun, error) {
+	return nil, nil
+}
+
+type rollupWindow struct {
+	from, to time.Time
+}
+
+type fakeRollupRepository struct {
+	repository.CheckRollupRepository
+	oldestHour time.Time
+	summaries  map[model.RollupResolution]repository.CheckLogSummary
+	windows    map[model.RollupResolution]rollupWindow
+}
+
+func (r *fakeRollupRepository) OldestBucket(resolution model.RollupResolution) (time.Time, error) {
+	return r.oldestHour, nil
+}
+
+func (r *fakeRollupRepository) Summarize(scope repository.Scope, resolution model.RollupResolution, ids []uint, from, to time.Time) ([]repository.CheckLogSummary, error) {
+	r.windows[resolution] = rollupWindow{from, to}
+	if !from.Before(to) {
+		return nil, nil
+	}
+	return []repository.CheckLogSummary{r.summaries[resolution]}, nil
+}
+
+func TestEndpointStatsCountsPrunedChecksFromRollups(t *testing.T) {
+	day := 24 * time.Hour
+	to := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-30 * day)
+	oldestRaw := to.Add(-7*day + 30*time.Minute)
+	oldestHour := to.Add(-14*day + 5*time.Hour)
+
+	endpoint := &model.Endpoint{URL: "https://example.com"}
+	endpoint.ID = 1
+	checkLogRepo := &fakeCheckLogRepository{
+		oldest:  oldestRaw,
+		summary: repository.CheckLogSummary{EndpointID: 1, Total: 100, Failed: 10, LatencyP50: 10, FirstAt: oldestRaw, LastAt: to},
+	}
+	rollupRepo := &fakeRollupRepository{
+		oldestHour: oldestHour,
+		summaries: map[model.RollupResolution]repository.CheckLogSummary{
+			model.RollupHourly: {EndpointID: 1, Total: 200, Failed: 0, LatencyP50: 20},
+			model.RollupDaily:  {EndpointID: 1, Total: 100, Failed: 10, LatencyP50: 40},
+		},
+		windows: make(map[model.RollupResolution]rollupWindow),
+	}
+	reports := NewReportService(&fakeEndpointRepository{endpoint: endpoint}, checkLogRepo, rollupRepo)
+
+	stats, err := reports.EndpointStats(repository.TeamScope(1), 1, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the check logs start with the first hour they cover whole
+	if want := to.Add(-7*day + time.Hour); !checkLogRepo.from.Equal(want) {
+		t.Fatalf("check logs summarized from %v, want %v", checkLogRepo.from, want)
+	}
+	// the hourly rollups start with the first day they cover whole
+	if want := (rollupWindow{to.Add(-13*day - 12*time.Hour), checkLogRepo.from}); rollupRepo.windows[model.RollupHourly] != want {
+		t.Fatalf("hourly rollups summarized over %v, want %v", rollupRepo.windows[model.RollupHourly], want)
+	}
+	if want := (rollupWindow{from, to.Add(-13*day - 12*time.Hour)}); rollupRepo.windows[model.RollupDaily] != want {
+		t.Fatalf("daily rollups summarized over %v, want %v", rollupRepo.windows[model.RollupDaily], want)
+	}
+
+	if stats.TotalChecks != 400 || stats.FailedChecks != 20 || stats.Uptime != 95 {
+		t.Fatalf("stats = %d checks, %d failed, %v uptime, want 400, 20, 95", stats.TotalChecks, stats.FailedChecks, stats.Uptime)
+	}
+	if stats.LatencyP50 != 22.5 {
+		t.Fatalf("p50 = %v, want the mean weighted by checks 22.5", stats.LatencyP50)
+	}
+}
+
+func TestEndpointStatsWithoutPruning(t *testing.T) {
+	to := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	endpoint := &model.Endpoint{}
+	endpoint.ID = 1
+	checkLogRepo := &fakeCheckLogRepository{
+		oldest:  from.Add(-time.Hour),
+		summary: repository.CheckLogSummary{EndpointID: 1, Total: 10, Failed: 1},
+	}
+	rollupRepo := &fakeRollupRepository{windows: make(map[model.RollupResolution]rollupWindow)}
+	reports := NewReportService(&fakeEndpointRepository{endpoint: endpoint}, checkLogRepo, rollupRepo)
+
+	stats, err := reports.EndpointStats(repository.TeamScope(1), 1, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkLogRepo.from.Equal(from) || len(rollupRepo.windows) != 0 {
+		t.Fatalf("rollups read although the check logs cover the window")
+	}
+	if stats.TotalChecks != 10 || stats.FailedChecks != 1 {
+		t.Fatalf("stats = %d checks, %d failed, want 10, 1", stats.TotalChecks, stats.FailedChecks)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// rollupChunk bounds the check logs aggregated by one rollup query.
+const rollupChunk = 24 * time.Hour
+
+var ErrInvalidRetention = errors.New("retention periods must not be negative, the interval and batch size must be positive")
+
+// BodyPolicy limits the response bodies stored with the check logs.
+type BodyPolicy struct {
+	MaxBytes   int  // bodies are truncated to this many bytes, 0 stores them whole
+	FailedOnly bool // the bodies of successful checks are not stored
+}
+
+// apply trims the body of a check log before it is stored, without splitting a UTF-8 sequence.
+func (p BodyPolicy) apply(checkLog *model.CheckLog) {
+	if p.FailedOnly && checkLog.Succeeded() {
+		checkLog.ResultBody = ""
+		return
+	}
+	if p.MaxBytes <= 0 || len(checkLog.ResultBody) <= p.MaxBytes {
+		return
+	}
+	end := p.MaxBytes
+	for end > 0 && !utf8.RuneStart(checkLog.ResultBody[end]) {
+		end--
+	}
+	checkLog.ResultBody = checkLog.ResultBody[:end]
+}
+
+// RetentionPolicy is how long check logs and their rollups are kept, 0 keeps them forever.
+type RetentionPolicy struct {
+	RawLogs       time.Duration
+	HourlyRollups time.Duration
+	DailyRollups  time.Duration
+	Interval      time.Duration // how often the rollups are built and old rows pruned
+	BatchSize     int           // rows deleted per statement
+}
+
+func (p RetentionPolicy) Validate() error {
+	if p.RawLogs < 0 || p.HourlyRollups < 0 || p.DailyRollups < 0 || p.Interval <= 0 || p.BatchSize <= 0 {
+		return ErrInvalidRetention
+	}
+	return nil
+}
+
+type RetentionService interface {
+	Shutdown()
+}
+
+type retentionService struct {
+	policy         RetentionPolicy
+	clusterService ClusterService
+	cancel         context.CancelFunc
+	checkLogRepo   repository.CheckLogRepository
+	rollupRepo     repository.CheckRollupRepository
+}
+
+// NewRetentionService rolls the check logs up into hourly and daily buckets every interval of
+// the policy and then prunes what outlived its retention. Check logs are only pruned once the
+// buckets covering them are built, so the logs of the current and previous UTC days are always
+// kept. In cluster mode the job runs on the leader alone.
+func NewRetentionService(
+	policy RetentionPolicy,
+	clusterService ClusterService,
+	wg *sync.WaitGroup,
+	checkLogRepo repository.CheckLogRepository,
+	rollupRepo repository.CheckRollupRepository,
+) (RetentionService, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retentionService := &retentionService{
+		policy:         policy,
+		clusterService: clusterService,
+		cancel:         cancel,
+		checkLogRepo:   checkLogRepo,
+		rollupRepo:     rollupRepo,
+	}
+
+	wg.Add(1)
+	go retentionService.schedule(ctx, wg)
+
+	return retentionService, nil
+}
+
+func (s *retentionService) Shutdown() {
+	s.cancel()
+}
+
+func (s *retentionService) schedule(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(s.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		if s.clusterService.Leader() {
+			s.run(ctx, time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *retentionService) run(ctx context.Context, now time.Time) {
+	for _, resolution := range []model.RollupResolution{model.RollupHourly, model.RollupDaily} {
+		if err := s.rollup(ctx, resolution, now); err != nil {
+			// the check logs are kept until they are rolled up
+			log.Println("failed to roll up check logs by", resolution, ", err:", err.Error())
+			return
+		}
+	}
+
+	if s.policy.RawLogs > 0 {
+		// the latest daily bucket is built again at the next run
+		before := now.Add(-s.policy.RawLogs)
+		if yesterday := model.RollupDaily.Bucket(now).Add(-24 * time.Hour); yesterday.Before(before) {
+			before = yesterday
+		}
+		s.prune(ctx, "check logs", func() (int64, error) {
+			return s.checkLogRepo.DeleteBefore(before, s.policy.BatchSize)
+		})
+	}
+	if s.policy.HourlyRollups > 0 {
+		before := now.Add(-s.policy.HourlyRollups)
+		s.prune(ctx, "hourly rollups", func() (int64, error) {
+			return s.rollupRepo.DeleteBefore(model.RollupHourly, before, s.policy.BatchSize)
+		})
+	}
+	if s.policy.DailyRollups > 0 {
+		before := now.Add(-s.policy.DailyRollups)
+		s.prune(ctx, "daily rollups", func() (int64, error) {
+			return s.rollupRepo.DeleteBefore(model.RollupDaily, before, s.policy.BatchSize)
+		})
+	}
+}
+
+// rollup builds the buckets completed since the latest one built, which is built again to count
+// the results that probes reported late.
+func (s *retentionService) rollup(ctx context.Context, resolution model.RollupResolution, now time.Time) error {
+	from, err := s.rollupRepo.LatestBucket(resolution)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		oldest, err := s.checkLogRepo.Oldest()
+		if err != nil || oldest.IsZero() {
+			return err
+		}
+		from = resolution.Bucket(oldest)
+	}
+
+	end := resolution.Bucket(now)
+	for from.Before(end) && ctx.Err() == nil {
+		to := resolution.Bucket(from.Add(rollupChunk))
+		if end.Before(to) {
+			to = end
+		}
+		if err := s.rollupRepo.Rollup(resolution, from, to); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// prune deletes batch by batch until a batch comes back short.
+func (s *retentionService) prune(ctx context.Context, what string, deleteBatch func() (int64, error)) {
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := deleteBatch()
+		if err != nil {
+			log.Println("failed to prune", what, ", err:", err.Error())
+			break
+		}
+		total += deleted
+		if deleted < int64(s.policy.BatchSize) {
+			break
+		}
+	}
+	if total > 0 {
+		log.Println("pruned", total, what)
+	}
+}
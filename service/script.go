@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"healthcheck/internal/model"
+	httpclient "healthcheck/pkg/http_client"
+	"healthcheck/pkg/jsonpath"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// runScript runs the steps of a scripted check in order within the endpoint timeout, stopping
+// at the first failing step. The returned response is the one of the last step run, with the
+// total duration of all the steps.
+func (r *checkRunner) runScript(ctx context.Context, endpoint *model.Endpoint) (*httpclient.Response, []model.StepResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(endpoint.Timeout)*time.Second)
+	defer cancel()
+
+	opts := r.httpOptions(endpoint)
+	variables := make(map[string]string)
+	results := make([]model.StepResult, 0, len(endpoint.Check.Steps))
+	res := &httpclient.Response{StatusCode: -1}
+	var total time.Duration
+
+	for i, step := range endpoint.Check.Steps {
+		name := step.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+
+		var err error
+		res, err = r.runStep(ctx, endpoint, &step, variables, opts)
+		total += res.Timings.Total
+		if err == nil {
+			err = evaluateCriteria(&step.SuccessCriteria, res)
+		}
+		if err == nil {
+			err = extract(step.Extract, res, variables)
+		}
+
+		result := model.StepResult{
+			Name:       name,
+			StatusCode: res.StatusCode,
+			Duration:   milliseconds(res.Timings.Total),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			res.Timings.Total = total
+			return res, results, stepError(name, err)
+		}
+		results = append(results, result)
+	}
+
+	res.Timings.Total = total
+	return res, results, nil
+}
+
+func (r *checkRunner) runStep(
+	ctx context.Context,
+	endpoint *model.Endpoint,
+	step *model.ScriptStep,
+	variables map[string]string,
+	opts httpclient.Options,
+) (*httpclient.Response, error) {
+	stepURL := substitute(step.URL, variables)
+	if strings.HasPrefix(stepURL, "/") {
+		stepURL = strings.TrimSuffix(endpoint.URL, "/") + stepURL
+	}
+	if _, err := url.Parse(stepURL); err != nil {
+		return &httpclient.Response{StatusCode: -1}, err
+	}
+
+	method := step.Method
+	if method == "" {
+		method = model.MethodGet
+	}
+
+	headers := make(map[string]string, len(endpoint.Headers)+len(step.Headers))
+	for k, v := range endpoint.Headers {
+		headers[k] = v
+	}
+	for k, v := range step.Headers {
+		headers[k] = substitute(v, variables)
+	}
+
+	body, err := stepBody(step.Body, variables)
+	if err != nil {
+		return &httpclient.Response{StatusCode: -1}, err
+	}
+
+	return r.httpClient.Do(ctx, string(method), stepURL, body, headers, opts)
+}
+
+// stepBody substitutes the variables in the body of a step. A structured body gets them in
+// its strings before being encoded, so that their values are escaped.
+func stepBody(body any, variables map[string]string) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(substitute(b, variables)), nil
+	default:
+		return json.Marshal(substituteValue(b, variables))
+	}
+}
+
+// substituteValue substitutes the variables in the keys and strings of a decoded json value.
+func substituteValue(value any, variables map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		return substitute(v, variables)
+	case map[string]any:
+		substituted := make(map[string]any, len(v))
+		for key, item := range v {
+			substituted[substitute(key, variables)] = substituteValue(item, variables)
+		}
+		return substituted
+	case []any:
+		substituted := make([]any, len(v))
+		for i, item := range v {
+			substituted[i] = substituteValue(item, variables)
+		}
+		return substituted
+	default:
+		return v
+	}
+}
+
+// substitute replaces the {{name}} references to known variables, unknown ones are left as is.
+func substitute(s string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func extract(extractions []model.Extraction, res *httpclient.Response, variables map[string]string) error {
+	var doc any
+	for _, extraction := range extractions {
+		assertion := "extract:" + extraction.Variable
+
+		switch extraction.From {
+		case model.ExtractJSONPath:
+			if doc == nil {
+				if err := json.Unmarshal(res.Body, &doc); err != nil {
+					return newAssertionError(assertion, "body is not valid json")
+				}
+			}
+			value, err := jsonpath.Lookup(doc, extraction.Expression)
+			if err != nil {
+				return newAssertionError(assertion, err.Error())
+			}
+			if s, ok := value.(string); ok {
+				variables[extraction.Variable] = s
+			} else {
+				encoded, _ := json.Marshal(value)
+				variables[extraction.Variable] = string(encoded)
+			}
+		case model.ExtractHeader:
+			value := res.Header.Get(http.CanonicalHeaderKey(extraction.Expression))
+			if value == "" {
+				return newAssertionError(assertion, fmt.Sprintf("header %s is missing", extraction.Expression))
+			}
+			variables[extraction.Variable] = value
+		case model.ExtractRegex:
+			re, err := regexp.Compile(extraction.Expression)
+			if err != nil {
+				return newAssertionError(assertion, err.Error())
+			}
+			match := re.FindSubmatch(res.Body)
+			if match == nil {
+				return newAssertionError(assertion, fmt.Sprintf("body does not match %q", extraction.Expression))
+			}
+			if len(match) > 1 {
+				variables[extraction.Variable] = string(match[1])
+			} else {
+				variables[extraction.Variable] = string(match[0])
+			}
+		}
+	}
+	return nil
+}
+
+// stepError names the step in err and in the failed assertion it carries, if any.
+func stepError(name string, err error) error {
+	var assertionErr *assertionError
+	if errors.As(err, &assertionErr) {
+		return &assertionError{"step:" + name + ":" + assertionErr.assertion, assertionErr.class, assertionErr.reason}
+	}
+	return fmt.Errorf("step %s: %w", name, err)
+}
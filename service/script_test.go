@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStepBodyEscapesVariables(t *testing.T) {
+	variables := map[string]string{"token": `a"b\c`, "name": `x", "admin": true, "y": "`}
+	tests := []struct {
+		name string
+		body any
+		want string
+	}{
+		{"string", `token={{token}}`, `token=a"b\c`},
+		{"object", map[string]any{
+			"token": "{{ token }}",
+			"user":  map[string]any{"name": "{{name}}", "tags": []any{"{{token}}", 1.0}},
+		}, `{"token":"a\"b\\c","user":{"name":"x\", \"admin\": true, \"y\": \"","tags":["a\"b\\c",1]}}`},
+		{"unknown variable", []any{"{{missing}}"}, `["{{missing}}"]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := stepBody(test.body, variables)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.want {
+				t.Fatalf("body = %s, want %s", body, test.want)
+			}
+		})
+	}
+
+	// the value is kept intact rather than adding fields to the document
+	body, _ := stepBody(map[string]any{"name": "{{name}}"}, variables)
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"name": variables["name"]}; !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("decoded body = %v, want %v", decoded, want)
+	}
+}
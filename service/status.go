@@ -0,0 +1,313 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusDays is the number of days of uptime history on the status page.
+	StatusDays = 90
+	// statusCacheTTL bounds how often the status page hits the database, it is public.
+	statusCacheTTL = time.Minute
+	// ungroupedComponents names the group of public endpoints that are not in any group.
+	ungroupedComponents = "Other"
+	// ungroupedID keys the ungrouped endpoints among the groups, group ids start at 1.
+	ungroupedID uint = 0
+)
+
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusOutage      = "outage"
+)
+
+// StatusSummary is what the status page shows, only public endpoints are included and only
+// by name.
+type StatusSummary struct {
+	Status    string            `json:"status"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Groups    []*GroupStatus    `json:"groups"`
+	Incidents []*PublicIncident `json:"incidents"`
+}
+
+type GroupStatus struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Status      string             `json:"status"`
+	Uptime      *float64           `json:"uptime"` // over StatusDays, nil without checks
+	Days        []DayStatus        `json:"days"`
+	Components  []*ComponentStatus `json:"components"`
+}
+
+type ComponentStatus struct {
+	Name   string      `json:"name"`
+	Status string      `json:"status"`
+	Uptime *float64    `json:"uptime"`
+	Days   []DayStatus `json:"days"`
+}
+
+type DayStatus struct {
+	Date   string   `json:"date"`   // UTC day, 2006-01-02
+	Uptime *float64 `json:"uptime"` // nil without checks
+	Checks int      `json:"checks"`
+	failed int
+}
+
+type PublicIncident struct {
+	Component    string    `json:"component"`
+	Group        string    `json:"group"`
+	StartedAt    time.Time `json:"started_at"`
+	Acknowledged bool      `json:"acknowledged"`
+}
+
+type StatusService interface {
+	Summary() (*StatusSummary, error)
+}
+
+type statusService struct {
+	endpointRepo repository.EndpointRepository
+	groupRepo    repository.EndpointGroupRepository
+	checkLogRepo repository.CheckLogRepository
+	rollupRepo   repository.CheckRollupRepository
+	incidentRepo repository.IncidentRepository
+
+	mu       sync.Mutex
+	summary  *StatusSummary
+	cachedAt time.Time
+}
+
+func NewStatusService(
+	endpointRepo repository.EndpointRepository,
+	groupRepo repository.EndpointGroupRepository,
+	checkLogRepo repository.CheckLogRepository,
+	rollupRepo repository.CheckRollupRepository,
+	incidentRepo repository.IncidentRepository,
+) StatusService {
+	return &statusService{
+		endpointRepo: endpointRepo,
+		groupRepo:    groupRepo,
+		checkLogRepo: checkLogRepo,
+		rollupRepo:   rollupRepo,
+		incidentRepo: incidentRepo,
+	}
+}
+
+// Summary returns the current status, cached for statusCacheTTL.
+func (s *statusService) Summary() (*StatusSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.summary != nil && time.Since(s.cachedAt) < statusCacheTTL {
+		return s.summary, nil
+	}
+	summary, err := s.buildSummary(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.summary, s.cachedAt = summary, time.Now()
+	return summary, nil
+}
+
+// countDaily counts the checks per day from the daily rollups, and from the check logs for the
+// days that are not rolled up yet.
+func (s *statusService) countDaily(ids []uint, from time.Time) ([]repository.DailyCheckCount, error) {
+	latest, err := s.rollupRepo.LatestBucket(model.RollupDaily)
+	if err != nil {
+		return nil, err
+	}
+	rawFrom := from
+	if !latest.Before(from) {
+		rawFrom = latest.Add(24 * time.Hour)
+	}
+
+	var counts []repository.DailyCheckCount
+	if rawFrom.After(from) {
+		rolledUp, err := s.rollupRepo.CountDaily(repository.AllTeams, ids, from)
+		if err != nil {
+			return nil, err
+		}
+		for _, count := range rolledUp {
+			if count.Day.Before(rawFrom) {
+				counts = append(counts, count)
+			}
+		}
+	}
+	raw, err := s.checkLogRepo.CountDaily(repository.AllTeams, ids, rawFrom)
+	if err != nil {
+		return nil, err
+	}
+	return append(counts, raw...), nil
+}
+
+// buildSummary spans every team, only the endpoints made public by their team are shown.
+func (s *statusService) buildSummary(now time.Time) (*StatusSummary, error) {
+	endpoints, err := s.endpointRepo.FetchAll(repository.AllTeams)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.groupRepo.FetchAll(repository.AllTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	var public []*model.Endpoint
+	var ids []uint
+	for _, endpoint := range endpoints {
+		if endpoint.Public && endpoint.ActiveCheck {
+			public = append(public, endpoint)
+			ids = append(ids, endpoint.ID)
+		}
+	}
+	sort.Slice(public, func(i, j int) bool { return public[i].Name < public[j].Name })
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(StatusDays - 1))
+	counts, err := s.countDaily(ids, from)
+	if err != nil {
+		return nil, err
+	}
+	daily := make(map[uint]map[string]repository.DailyCheckCount, len(public))
+	for _, count := range counts {
+		if daily[count.EndpointID] == nil {
+			daily[count.EndpointID] = make(map[string]repository.DailyCheckCount)
+		}
+		daily[count.EndpointID][count.Day.UTC().Format(time.DateOnly)] = count
+	}
+
+	// the groups are keyed by id, groups of different teams may share a name
+	byGroup := make(map[uint]*GroupStatus, len(groups)+1)
+	summary := &StatusSummary{UpdatedAt: now, Groups: []*GroupStatus{}, Incidents: []*PublicIncident{}}
+	for _, group := range groups {
+		byGroup[group.ID] = &GroupStatus{Name: group.Name, Description: group.Description}
+	}
+	byGroup[ungroupedID] = &GroupStatus{Name: ungroupedComponents}
+
+	componentGroups := make(map[uint]string, len(public))
+	for _, endpoint := range public {
+		group := byGroup[ungroupedID]
+		if endpoint.GroupID != nil {
+			if g, ok := byGroup[*endpoint.GroupID]; ok {
+				group = g
+			}
+		}
+		componentGroups[endpoint.ID] = group.Name
+
+		component := &ComponentStatus{
+			Name:   endpoint.Name,
+			Status: StatusOutage,
+			Days:   make([]DayStatus, StatusDays),
+		}
+		if endpoint.LastStatus {
+			component.Status = StatusOperational
+		}
+		for i := range component.Days {
+			date := from.AddDate(0, 0, i).Format(time.DateOnly)
+			count := daily[endpoint.ID][date]
+			component.Days[i] = DayStatus{Date: date, Checks: count.Total, failed: count.Failed}
+			component.Days[i].Uptime = dayUptime(count.Total, count.Failed)
+		}
+		component.Uptime = daysUptime(component.Days)
+		group.Components = append(group.Components, component)
+	}
+
+	// groups without public endpoints are not shown, the ungrouped ones come last
+	var operational, outage int
+	for _, group := range groups {
+		if g := byGroup[group.ID]; len(g.Components) > 0 {
+			summary.Groups = append(summary.Groups, g)
+		}
+	}
+	if g := byGroup[ungroupedID]; len(g.Components) > 0 {
+		summary.Groups = append(summary.Groups, g)
+	}
+	for _, group := range summary.Groups {
+		summarizeGroup(group)
+		for _, component := range group.Components {
+			if component.Status == StatusOperational {
+				operational++
+			} else {
+				outage++
+			}
+		}
+	}
+	summary.Status = overallStatus(operational, outage)
+
+	open := true
+	incidents, err := s.incidentRepo.FetchAll(repository.AllTeams, repository.IncidentFilter{Open: &open})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(public))
+	for _, endpoint := range public {
+		names[endpoint.ID] = endpoint.Name
+	}
+	for _, incident := range incidents {
+		name, ok := names[incident.EndpointID]
+		if !ok {
+			continue
+		}
+		summary.Incidents = append(summary.Incidents, &PublicIncident{
+			Component:    name,
+			Group:        componentGroups[incident.EndpointID],
+			StartedAt:    incident.StartedAt,
+			Acknowledged: incident.Acknowledged,
+		})
+	}
+
+	return summary, nil
+}
+
+// summarizeGroup derives the status and daily uptime of a group from its components.
+func summarizeGroup(group *GroupStatus) {
+	var operational, outage int
+	group.Days = make([]DayStatus, StatusDays)
+	for _, component := range group.Components {
+		if component.Status == StatusOperational {
+			operational++
+		} else {
+			outage++
+		}
+		for i, day := range component.Days {
+			group.Days[i].Date = day.Date
+			group.Days[i].Checks += day.Checks
+			group.Days[i].failed += day.failed
+		}
+	}
+	for i := range group.Days {
+		group.Days[i].Uptime = dayUptime(group.Days[i].Checks, group.Days[i].failed)
+	}
+	group.Uptime = daysUptime(group.Days)
+	group.Status = overallStatus(operational, outage)
+}
+
+func overallStatus(operational, outage int) string {
+	switch {
+	case outage == 0:
+		return StatusOperational
+	case operational == 0:
+		return StatusOutage
+	default:
+		return StatusDegraded
+	}
+}
+
+func dayUptime(total, failed int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	value := uptime(total, failed)
+	return &value
+}
+
+func daysUptime(days []DayStatus) *float64 {
+	var total, failed int
+	for _, day := range days {
+		total += day.Checks
+		failed += day.failed
+	}
+	return dayUptime(total, failed)
+}
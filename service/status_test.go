@@ -0,0 +1,93 @@
+package service
+
+import (
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"testing"
+	"time"
+)
+
+type statusEndpointRepository struct {
+	repository.EndpointRepository
+	endpoints []*model.Endpoint
+}
+
+func (r *statusEndpointRepository) FetchAll(scope repository.Scope) ([]*model.Endpoint, error) {
+	return r.endpoints, nil
+}
+
+// statusCheckLogRepository and statusRollupRepository hold no checks.
+type statusCheckLogRepository struct {
+	repository.CheckLogRepository
+}
+
+func (r *statusCheckLogRepository) CountDaily(scope repository.Scope, ids []uint, from time.Time) ([]repository.DailyCheckCount, error) {
+	return nil, nil
+}
+
+type statusRollupRepository struct {
+	repository.CheckRollupRepository
+}
+
+func (r *statusRollupRepository) LatestBucket(resolution model.RollupResolution) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+type statusIncidentRepository struct {
+	repository.IncidentRepository
+	incidents []*model.Incident
+}
+
+func (r *statusIncidentRepository) FetchAll(scope repository.Scope, filter repository.IncidentFilter) ([]*model.Incident, error) {
+	return r.incidents, nil
+}
+
+func TestStatusSummaryKeepsGroupsApart(t *testing.T) {
+	group := func(id, teamID uint, name string) *model.EndpointGroup {
+		group := &model.EndpointGroup{TeamID: teamID, Name: name}
+		group.ID = id
+		return group
+	}
+	endpoint := func(id uint, name string, groupID *uint) *model.Endpoint {
+		endpoint := &model.Endpoint{Name: name, GroupID: groupID, Public: true, ActiveCheck: true, LastStatus: true}
+		endpoint.ID = id
+		return endpoint
+	}
+	apiA, apiB, other := uint(1), uint(2), uint(3)
+
+	// two teams each have an "API" group, a third group is named like the ungrouped endpoints
+	service := NewStatusService(
+		&statusEndpointRepository{endpoints: []*model.Endpoint{
+			endpoint(1, "a", &apiA),
+			endpoint(2, "b", &apiB),
+			endpoint(3, "c", &other),
+			endpoint(4, "d", nil),
+		}},
+		&fakeGroupRepository{groups: []*model.EndpointGroup{
+			group(apiA, 1, "API"),
+			group(apiB, 2, "API"),
+			group(other, 2, ungroupedComponents),
+		}},
+		&statusCheckLogRepository{},
+		&statusRollupRepository{},
+		&statusIncidentRepository{incidents: []*model.Incident{{EndpointID: 2}}},
+	).(*statusService)
+
+	summary, err := service.buildSummary(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"API", "a"}, {"API", "b"}, {ungroupedComponents, "c"}, {ungroupedComponents, "d"}}
+	if len(summary.Groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(summary.Groups), len(want))
+	}
+	for i, group := range summary.Groups {
+		if group.Name != want[i][0] || len(group.Components) != 1 || group.Components[0].Name != want[i][1] {
+			t.Fatalf("group %d is %s with %d components, want %s with %s", i, group.Name, len(group.Components), want[i][0], want[i][1])
+		}
+	}
+	if len(summary.Incidents) != 1 || summary.Incidents[0].Group != "API" {
+		t.Fatalf("incidents = %v, want one in API", summary.Incidents)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"healthcheck/internal/model"
+	"healthcheck/internal/repository"
+	"log"
+	"time"
+)
+
+var (
+	ErrDefaultTeam   = errors.New("the default team cannot be deleted")
+	ErrTeamNotEmpty  = errors.New("team still has endpoints, users or api keys")
+	ErrTeamNameTaken = errors.New("team name is already taken")
+)
+
+type TeamService interface {
+	// DefaultTeam owns what was created before teams existed, its admins manage the teams.
+	DefaultTeamID() uint
+	CreateTeam(name string, maxEndpoints, minInterval int) (*model.Team, error)
+	FetchAllTeams() ([]*model.Team, error)
+	GetTeam(id uint) (*model.Team, error)
+	UpdateTeam(id uint, name string, maxEndpoints, minInterval int) error
+	DeleteTeam(id uint) error
+}
+
+type teamService struct {
+	defaultTeamID uint
+	teamRepo      repository.TeamRepository
+	endpointRepo  repository.EndpointRepository
+	userRepo      repository.UserRepository
+	apiKeyRepo    repository.APIKeyRepository
+}
+
+// NewTeamService creates the team service along with the default team when it does not exist
+// yet, and assigns the rows that belong to no team to it.
+func NewTeamService(
+	defaultTeamName string,
+	teamRepo repository.TeamRepository,
+	endpointRepo repository.EndpointRepository,
+	userRepo repository.UserRepository,
+	apiKeyRepo repository.APIKeyRepository,
+) (TeamService, error) {
+	team, err := teamRepo.FetchByName(defaultTeamName)
+	if errors.Is(err, repository.ErrNotFound) {
+		team = &model.Team{Name: defaultTeamName}
+		err = teamRepo.Create(team)
+		if err == nil {
+			log.Println("default team", defaultTeamName, "created")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := teamRepo.Adopt(team.ID); err != nil {
+		return nil, err
+	}
+
+	return &teamService{team.ID, teamRepo, endpointRepo, userRepo, apiKeyRepo}, nil
+}
+
+func (s *teamService) DefaultTeamID() uint {
+	return s.defaultTeamID
+}
+
+func (s *teamService) CreateTeam(name string, maxEndpoints, minInterval int) (*model.Team, error) {
+	team := &model.Team{Name: name, MaxEndpoints: maxEndpoints, MinInterval: minInterval}
+	if err := team.ValidateQuotas(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameAvailable(team); err != nil {
+		return nil, err
+	}
+
+	if err := s.teamRepo.Create(team); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *teamService) FetchAllTeams() ([]*model.Team, error) {
+	teams, err := s.teamRepo.FetchAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (s *teamService) GetTeam(id uint) (*model.Team, error) {
+	team, err := s.teamRepo.FetchByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+// UpdateTeam changes the name and quotas of a team, lowered quotas apply to the endpoints that
+// are created or updated afterwards.
+func (s *teamService) UpdateTeam(id uint, name string, maxEndpoints, minInterval int) error {
+	team, err := s.teamRepo.FetchByID(id)
+	if err != nil {
+		return err
+	}
+
+	team.Name = name
+	team.MaxEndpoints = maxEndpoints
+	team.MinInterval = minInterval
+	if err := team.ValidateQuotas(); err != nil {
+		return err
+	}
+
+	if err := s.checkNameAvailable(team); err != nil {
+		return err
+	}
+
+	if err := s.teamRepo.Update(team); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeam removes a team that no longer has endpoints, users or usable api keys.
+func (s *teamService) DeleteTeam(id uint) error {
+	if id == s.defaultTeamID {
+		return ErrDefaultTeam
+	}
+	if _, err := s.teamRepo.FetchByID(id); err != nil {
+		return err
+	}
+
+	scope := repository.TeamScope(id)
+	endpoints, err := s.endpointRepo.Count(scope)
+	if err != nil {
+		return err
+	}
+	users, err := s.userRepo.FetchAll(scope)
+	if err != nil {
+		return err
+	}
+	apiKeys, err := s.apiKeyRepo.FetchAll(scope)
+	if err != nil {
+		return err
+	}
+	if endpoints > 0 || len(users) > 0 {
+		return ErrTeamNotEmpty
+	}
+	now := time.Now()
+	for _, apiKey := range apiKeys {
+		if apiKey.Usable(now) {
+			return ErrTeamNotEmpty
+		}
+	}
+
+	if err := s.teamRepo.Delete(id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkNameAvailable makes sure no other team has the name of team.
+func (s *teamService) checkNameAvailable(team *model.Team) error {
+	other, err := s.teamRepo.FetchByName(team.Name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID != team.ID {
+		return ErrTeamNameTaken
+	}
+	return nil
+}